@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+const asfAllowTrustLineClawback = 16
+
+// checkClawbackAllowed stops before setting asfAllowTrustLineClawback on an
+// account that has trust lines or owns other ledger objects, which rippled
+// refuses with tecOWNERS.
+func checkClawbackAllowed(c *cli.Context) {
+	account := keyAccount(c)
+	r := newRemote(c)
+	lines, err := r.AccountLines(*account, "validated")
+	checkErr(err)
+	if len(lines.Lines) > 0 {
+		checkFailed(c, "%s has %d trust lines, clawback can only be enabled before the first", account, len(lines.Lines))
+		return
+	}
+	info, err := r.AccountInfo(*account)
+	checkErr(err)
+	if info.AccountData.OwnerCount != nil && *info.AccountData.OwnerCount > 0 {
+		checkFailed(c, "%s owns %d ledger objects, clawback can only be enabled by an account that owns none", account, *info.AccountData.OwnerCount)
+	}
+}
+
+// accountSet sets or clears one of the account's AccountSet flags.
+func accountSet(c *cli.Context) {
+	if (c.String("set") == "" && c.String("clear") == "") || !haveSigner(c) {
+		fmt.Println("A flag to set or clear and seed are required")
+		os.Exit(1)
+	}
+	tx := txlib.NewAccountSet()
+	if c.String("set") != "" {
+		flag, err := parseAccountSetFlag(c.String("set"))
+		checkErr(err)
+		if flag == asfAllowTrustLineClawback {
+			checkClawbackAllowed(c)
+		}
+		tx.SetFlag = &flag
+	}
+	if c.String("clear") != "" {
+		flag, err := parseAccountSetFlag(c.String("clear"))
+		checkErr(err)
+		if flag == asfAllowTrustLineClawback {
+			checkErr(fmt.Errorf("asfAllowTrustLineClawback cannot be cleared once set"))
+		}
+		tx.ClearFlag = &flag
+	}
+	sign(c, tx)
+	outputTx(c, tx)
+}
+
+// clawbackEnable sets asfAllowTrustLineClawback, letting the issuer claw
+// back the tokens it issues. It can only be set while the account has no
+// trust lines, and never cleared.
+func clawbackEnable(c *cli.Context) {
+	if !haveSigner(c) {
+		fmt.Println("Seed is required")
+		os.Exit(1)
+	}
+	checkClawbackAllowed(c)
+	tx := txlib.NewAccountSet()
+	flag := uint32(asfAllowTrustLineClawback)
+	tx.SetFlag = &flag
+	sign(c, tx)
+	outputTx(c, tx)
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+// addressEntry is a named destination. The tag is used when a transaction
+// gives none.
+type addressEntry struct {
+	Address string  `json:"address"`
+	Tag     *uint32 `json:"tag,omitempty"`
+	Notes   string  `json:"notes,omitempty"`
+}
+
+// The --addressbook file, set before the commands run
+var addressBookFile string
+
+// The PayString environment for --network, set before the commands run
+var payStringEnvironment = "mainnet"
+
+var payStringEnvironments = map[string]string{
+	"main": "mainnet",
+	"test": "testnet",
+	"dev":  "devnet",
+}
+
+func addressBookPath() string {
+	if addressBookFile != "" {
+		return addressBookFile
+	}
+	dir, err := os.UserConfigDir()
+	checkErr(err)
+	return filepath.Join(dir, "tx", "addressbook.json")
+}
+
+func readAddressBook() map[string]addressEntry {
+	book := make(map[string]addressEntry)
+	b, err := ioutil.ReadFile(addressBookPath())
+	if os.IsNotExist(err) {
+		return book
+	}
+	checkErr(err)
+	checkErr(json.Unmarshal(b, &book))
+	return book
+}
+
+func writeAddressBook(book map[string]addressEntry) {
+	b, err := json.MarshalIndent(book, "", "  ")
+	checkErr(err)
+	path := addressBookPath()
+	checkErr(os.MkdirAll(filepath.Dir(path), 0700))
+	checkErr(ioutil.WriteFile(path, append(b, '\n'), 0600))
+}
+
+// isAlias reports whether s names an address book entry, as @name.
+func isAlias(s string) bool {
+	return strings.HasPrefix(s, "@")
+}
+
+// resolveAddress parses a classic address, an X-address, an @name from the
+// address book or a PayString, returning the tag it gives. PayStrings are
+// looked up over HTTPS, so the result is printed to stderr for checking.
+func resolveAddress(s string) (*data.Account, *uint32, error) {
+	if txlib.IsPayString(s) {
+		account, tag, err := txlib.ResolvePayString(s, payStringEnvironment)
+		if err != nil {
+			return nil, nil, err
+		}
+		if tag != nil {
+			fmt.Fprintf(os.Stderr, "%s is %s tag %d\n", s, account, *tag)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s is %s\n", s, account)
+		}
+		return account, tag, nil
+	}
+	if !isAlias(s) {
+		return txlib.ParseAddress(s)
+	}
+	entry, ok := readAddressBook()[s[1:]]
+	if !ok {
+		return nil, nil, fmt.Errorf("No address book entry %s", s)
+	}
+	account, tag, err := txlib.ParseAddress(entry.Address)
+	if err != nil {
+		return nil, nil, err
+	}
+	if entry.Tag != nil {
+		tag = entry.Tag
+	}
+	return account, tag, nil
+}
+
+func addressBookAdd(c *cli.Context) {
+	if len(c.Args()) != 2 {
+		fmt.Println("Name and address are required")
+		os.Exit(1)
+	}
+	name, address := c.Args()[0], c.Args()[1]
+	if name == "" || strings.ContainsAny(name, "@ \t") {
+		checkErr(fmt.Errorf("Bad address book name: %q", name))
+	}
+	_, tag, err := txlib.ParseAddress(address)
+	checkErr(err)
+	book := readAddressBook()
+	if _, ok := book[name]; ok && !c.Bool("force") {
+		checkErr(fmt.Errorf("%s is already in the address book, use --force to replace it", name))
+	}
+	entry := addressEntry{Address: address, Notes: c.String("notes")}
+	if c.IsSet("tag") {
+		if tag != nil && *tag != uint32(c.Int("tag")) {
+			checkErr(fmt.Errorf("Tag %d conflicts with the X-address tag %d", c.Int("tag"), *tag))
+		}
+		entry.Tag = new(uint32)
+		*entry.Tag = uint32(c.Int("tag"))
+	}
+	book[name] = entry
+	writeAddressBook(book)
+	fmt.Printf("Saved @%s for %s\n", name, address)
+}
+
+func addressBookList(c *cli.Context) {
+	book := readAddressBook()
+	if c.GlobalBool("json") {
+		outputJSON(book)
+		return
+	}
+	var names []string
+	for name := range book {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if outputTable {
+		var rows [][]string
+		for _, name := range names {
+			entry := book[name]
+			tag := ""
+			if entry.Tag != nil {
+				tag = fmt.Sprint(*entry.Tag)
+			}
+			rows = append(rows, []string{"@" + name, entry.Address, tag, entry.Notes})
+		}
+		printTable([]string{"NAME", "ADDRESS", "TAG", "NOTES"}, rows)
+		return
+	}
+	for _, name := range names {
+		entry := book[name]
+		line := fmt.Sprintf("@%s %s", name, entry.Address)
+		if entry.Tag != nil {
+			line += fmt.Sprintf(" tag %d", *entry.Tag)
+		}
+		if entry.Notes != "" {
+			line += " # " + entry.Notes
+		}
+		fmt.Println(line)
+	}
+}
+
+func addressBookRemove(c *cli.Context) {
+	name := strings.TrimPrefix(c.Args().First(), "@")
+	if name == "" {
+		fmt.Println("Name is required")
+		os.Exit(1)
+	}
+	book := readAddressBook()
+	if _, ok := book[name]; !ok {
+		checkErr(fmt.Errorf("No address book entry @%s", name))
+	}
+	delete(book, name)
+	writeAddressBook(book)
+}
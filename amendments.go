@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/crypto"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+// The index of the Amendments ledger entry, which lists the enabled
+// amendments and those with a majority of validators
+const amendmentsIndex = "7DB0788C020F02780A673DC74757F23823FA3014C1866E72CC4CD8B226CD6EF4"
+
+// An amendment is enabled once it has held a majority this long.
+const amendmentMajorityTime = 14 * 24 * time.Hour
+
+// Amendment names, as rippled knows them. An amendment's id is the
+// SHA-512Half of its name.
+var amendmentNames = []string{
+	"AMM", "AMMClawback", "CheckCashMakesTrustLine", "Checks", "Clawback",
+	"Credentials", "CryptoConditions", "CryptoConditionsSuite", "DID",
+	"DeepFreeze", "DeletableAccounts", "DepositAuth", "DepositPreauth",
+	"DisallowIncoming", "DynamicNFT", "EnforceInvariants", "Escrow",
+	"ExpandedSignerList", "FeeEscalation", "Flow", "FlowCross",
+	"FlowSortStrands", "HardenedValidations", "ImmediateOfferKilled",
+	"InvariantsV1_1", "MPTokensV1", "MultiSign", "MultiSignReserve",
+	"NFTokenMintOffer", "NegativeUNL", "NonFungibleTokensV1",
+	"NonFungibleTokensV1_1", "OwnerPaysFee", "PayChan", "PermissionedDomains",
+	"PriceOracle", "RequireFullyCanonicalSig", "SHAMapV2", "SortedDirectories",
+	"TicketBatch", "TickSize", "Tickets", "TrustSetAuth", "XChainBridge",
+	"XRPFees", "fix1201", "fix1368", "fix1373", "fix1512", "fix1513",
+	"fix1515", "fix1523", "fix1528", "fix1543", "fix1571", "fix1578",
+	"fix1623", "fix1781", "fixAMMOverflowOffer", "fixAMMv1_1", "fixAMMv1_2",
+	"fixAmendmentMajorityCalc", "fixCheckThreading", "fixDisallowIncomingV1",
+	"fixEmptyDID", "fixEnforceNFTokenTrustline", "fixFillOrKill",
+	"fixInnerObjTemplate", "fixInnerObjTemplate2", "fixMasterKeyAsRegularKey",
+	"fixNFTokenDirV1", "fixNFTokenPageLinks", "fixNFTokenRemint",
+	"fixNFTokenReserve", "fixNonFungibleTokensV1_2", "fixPayChanRecipientOwnerDir",
+	"fixPreviousTxnID", "fixQualityUpperBound", "fixReducedOffersV1",
+	"fixReducedOffersV2", "fixRemoveNFTokenAutoTrustLine",
+	"fixRmSmallIncreasedQOffers", "fixSTAmountCanonicalize",
+	"fixTakerDryOfferRemoval", "fixTrustLinesToSelf", "fixUniversalNumber",
+	"fixXChainRewardRounding",
+}
+
+// amendmentName returns the name of an amendment id, or the id if it is
+// not known.
+func amendmentName(id data.Hash256) string {
+	for _, name := range amendmentNames {
+		if string(crypto.Sha512Half([]byte(name))) == string(id.Bytes()) {
+			return name
+		}
+	}
+	return id.String()
+}
+
+type amendment struct {
+	ID      data.Hash256 `json:"id"`
+	Name    string       `json:"name"`
+	Enabled bool         `json:"enabled"`
+	// For amendments with a majority, when it was gained and when the
+	// amendment will be enabled if it keeps it
+	MajoritySince *time.Time `json:"majority_since,omitempty"`
+	EnabledAfter  *time.Time `json:"enabled_after,omitempty"`
+}
+
+type amendmentsEntry struct {
+	Node struct {
+		Amendments []data.Hash256 `json:"Amendments"`
+		Majorities []struct {
+			Majority struct {
+				Amendment data.Hash256 `json:"Amendment"`
+				CloseTime uint32       `json:"CloseTime"`
+			} `json:"Majority"`
+		} `json:"Majorities"`
+	} `json:"node"`
+	LedgerIndex uint32 `json:"ledger_index"`
+}
+
+// amendments lists the enabled amendments and those with a majority of
+// validators, from the validated ledger.
+func amendments(c *cli.Context) {
+	var entry amendmentsEntry
+	checkErr(request(c, "ledger_entry", map[string]interface{}{
+		"index":        amendmentsIndex,
+		"ledger_index": "validated",
+	}, &entry))
+
+	var list []amendment
+	for _, m := range entry.Node.Majorities {
+		since := time.Unix(int64(m.Majority.CloseTime)+txlib.RippleEpoch, 0).UTC()
+		after := since.Add(amendmentMajorityTime)
+		list = append(list, amendment{ID: m.Majority.Amendment, Name: amendmentName(m.Majority.Amendment), MajoritySince: &since, EnabledAfter: &after})
+	}
+	for _, id := range entry.Node.Amendments {
+		list = append(list, amendment{ID: id, Name: amendmentName(id), Enabled: true})
+	}
+
+	if c.GlobalBool("json") {
+		outputJSON(list)
+		return
+	}
+	if outputTable {
+		var rows [][]string
+		for _, a := range list {
+			status := "enabled"
+			if !a.Enabled {
+				status = "majority, enabled after " + a.EnabledAfter.Format(time.RFC3339)
+			}
+			rows = append(rows, []string{a.Name, a.ID.String(), status})
+		}
+		printTable([]string{"NAME", "ID", "STATUS"}, rows)
+		return
+	}
+	fmt.Printf("Ledger: %d\n", entry.LedgerIndex)
+	for _, a := range list {
+		if a.Enabled {
+			fmt.Printf("Enabled: %s\n", a.Name)
+		} else {
+			fmt.Printf("Pending: %s majority since %s, enabled after %s\n", a.Name, a.MajoritySince.Format(time.RFC3339), a.EnabledAfter.Format(time.RFC3339))
+		}
+	}
+}
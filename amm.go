@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+)
+
+type ammInfoResult struct {
+	AMM struct {
+		Account     data.Account `json:"account"`
+		Amount      data.Amount  `json:"amount"`
+		Amount2     data.Amount  `json:"amount2"`
+		LPToken     data.Amount  `json:"lp_token"`
+		TradingFee  uint16       `json:"trading_fee"`
+		AuctionSlot *struct {
+			Account       data.Account `json:"account"`
+			DiscountedFee uint16       `json:"discounted_fee"`
+			Expiration    string       `json:"expiration"`
+			Price         data.Amount  `json:"price"`
+			TimeInterval  uint32       `json:"time_interval"`
+			AuthAccounts  []struct {
+				Account data.Account `json:"account"`
+			} `json:"auth_accounts"`
+		} `json:"auction_slot,omitempty"`
+		VoteSlots []struct {
+			Account    data.Account `json:"account"`
+			TradingFee uint16       `json:"trading_fee"`
+			VoteWeight uint32       `json:"vote_weight"`
+		} `json:"vote_slots"`
+	} `json:"amm"`
+	LedgerIndex uint32 `json:"ledger_index"`
+}
+
+// formatTradingFee converts a fee in units of 1/100,000 to a percentage.
+func formatTradingFee(fee uint16) string {
+	return fmt.Sprintf("%.3f%%", float64(fee)/1000)
+}
+
+func parseAsset(s string) *data.Asset {
+	asset, err := data.NewAsset(s)
+	checkErr(err)
+	return asset
+}
+
+func ammInfo(c *cli.Context) {
+	params := map[string]interface{}{"ledger_index": "validated"}
+	switch {
+	case c.String("account") != "":
+		params["amm_account"] = parseAccount(c.String("account")).String()
+	case len(c.Args()) == 2:
+		params["asset"] = parseAsset(c.Args()[0])
+		params["asset2"] = parseAsset(c.Args()[1])
+	default:
+		fmt.Println("Two assets or an AMM account are required")
+		os.Exit(1)
+	}
+
+	var result ammInfoResult
+	checkErr(request(c, "amm_info", params, &result))
+
+	if c.GlobalBool("json") {
+		outputJSON(result)
+		return
+	}
+
+	amm := result.AMM
+	fmt.Printf("Account: %s Ledger: %d\n", amm.Account, result.LedgerIndex)
+	fmt.Printf("Pool: %s\n      %s\n", amm.Amount, amm.Amount2)
+	fmt.Printf("LP tokens: %s\n", amm.LPToken)
+	fmt.Printf("Trading fee: %s\n", formatTradingFee(amm.TradingFee))
+	if slot := amm.AuctionSlot; slot != nil {
+		fmt.Printf("Auction slot: %s Price: %s Discounted fee: %s Expires: %s\n", slot.Account, slot.Price, formatTradingFee(slot.DiscountedFee), slot.Expiration)
+		for _, auth := range slot.AuthAccounts {
+			fmt.Printf("  Authorized: %s\n", auth.Account)
+		}
+	}
+	for _, vote := range amm.VoteSlots {
+		fmt.Printf("Vote: %s Fee: %s Weight: %d\n", vote.Account, formatTradingFee(vote.TradingFee), vote.VoteWeight)
+	}
+}
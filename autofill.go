@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/ripple/websockets"
+)
+
+// How many ledgers an autofilled transaction stays valid for
+const autofillLedgers = 20
+
+type feeResult struct {
+	Drops struct {
+		BaseFee       string `json:"base_fee"`
+		MedianFee     string `json:"median_fee"`
+		MinimumFee    string `json:"minimum_fee"`
+		OpenLedgerFee string `json:"open_ledger_fee"`
+	} `json:"drops"`
+	CurrentLedgerSize  string `json:"current_ledger_size"`
+	CurrentQueueSize   string `json:"current_queue_size"`
+	ExpectedLedgerSize string `json:"expected_ledger_size"`
+	MaxQueueSize       string `json:"max_queue_size"`
+	LedgerCurrentIndex uint32 `json:"ledger_current_index"`
+}
+
+// autofill sets the Sequence, Fee and LastLedgerSequence of tx from the
+// server, unless they are already set or given by flags. The account is the
+// one the transaction will be signed for.
+func autofill(c *cli.Context, tx data.Transaction) {
+	base := tx.GetBase()
+	account := base.Account
+	if c.GlobalString("account") != "" || account.IsZero() {
+		account = *keyAccount(c)
+	}
+	info, err := newRemote(c).AccountInfo(account)
+	checkErr(err)
+
+	if base.Sequence == 0 && !c.GlobalIsSet("sequence") && info.AccountData.Sequence != nil {
+		base.Sequence = *info.AccountData.Sequence
+	}
+	if base.Fee.IsZero() && !c.GlobalIsSet("fee") {
+		fee, err := openLedgerFee(c)
+		checkErr(err)
+		base.Fee = *fee
+	}
+	if base.LastLedgerSequence == nil && !c.GlobalIsSet("lastledger") {
+		base.LastLedgerSequence = new(uint32)
+		*base.LastLedgerSequence = info.LedgerSequence + autofillLedgers
+	}
+}
+
+// openLedgerDrops returns the fee in drops the server currently needs to
+// get a transaction into the open ledger.
+func openLedgerDrops(c *cli.Context) (int64, error) {
+	var fee feeResult
+	if err := request(c, "fee", nil, &fee); err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(fee.Drops.OpenLedgerFee, 10, 64)
+}
+
+func openLedgerFee(c *cli.Context) (*data.Value, error) {
+	drops, err := openLedgerDrops(c)
+	if err != nil {
+		return nil, err
+	}
+	return data.NewNativeValue(drops)
+}
+
+// accountTxnID resolves --account-txn-id, fetching the hash of the
+// account's last transaction for "auto". The account must have
+// asfAccountTxnID set for the ledger to track it.
+func accountTxnID(c *cli.Context, account data.Account) (*data.Hash256, error) {
+	if c.GlobalString("account-txn-id") != "auto" {
+		return data.NewHash256(c.GlobalString("account-txn-id"))
+	}
+	r, err := websockets.NewRemote(server(c))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	info, err := r.AccountInfo(account)
+	if err != nil {
+		return nil, err
+	}
+	if info.AccountData.AccountTxnID == nil {
+		return nil, fmt.Errorf("%s does not track its last transaction, set asfAccountTxnID first", account)
+	}
+	return info.AccountData.AccountTxnID, nil
+}
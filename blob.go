@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/crypto"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+func readInput(c *cli.Context) string {
+	s := c.Args().First()
+	if s == "" {
+		b, err := ioutil.ReadAll(os.Stdin)
+		checkErr(err)
+		s = string(b)
+	}
+	return strings.TrimSpace(s)
+}
+
+// readBlob returns the hex blob given as the first argument, or on stdin.
+func readBlob(c *cli.Context) []byte {
+	b, err := hex.DecodeString(readInput(c))
+	checkErr(err)
+	return b
+}
+
+// readTx reads a transaction given either as a hex blob or as JSON.
+func readTx(c *cli.Context) data.Transaction {
+	return parseTx(readInput(c))
+}
+
+// parseTx parses a transaction given either as a hex blob or as JSON.
+func parseTx(s string) data.Transaction {
+	if !strings.HasPrefix(s, "{") {
+		b, err := hex.DecodeString(s)
+		checkErr(err)
+		return decodeTx(b)
+	}
+	tx, err := txlib.ParseTransactionJSON([]byte(s))
+	checkErr(err)
+	return tx
+}
+
+// parseTxFile parses a transaction file holding a hex blob, JSON or the
+// binary encoding.
+func parseTxFile(b []byte) data.Transaction {
+	s := strings.TrimSpace(string(b))
+	if _, err := hex.DecodeString(s); err == nil || strings.HasPrefix(s, "{") {
+		return parseTx(s)
+	}
+	return decodeTx(b)
+}
+
+func decodeTx(b []byte) data.Transaction {
+	tx, err := txlib.DecodeTransaction(b)
+	checkErr(err)
+	return tx
+}
+
+func decode(c *cli.Context) {
+	tx := decodeTx(readBlob(c))
+
+	b, err := json.Marshal(tx)
+	checkErr(err)
+	var fields map[string]interface{}
+	checkErr(json.Unmarshal(b, &fields))
+	if flags := tx.GetBase().Flags; flags != nil && *flags != 0 {
+		fields["FlagNames"] = explainTxFlags(tx.GetType(), uint32(*flags))
+	}
+	if accountSet, ok := tx.(*data.AccountSet); ok && c.Bool("verbose") {
+		if accountSet.SetFlag != nil {
+			fields["SetFlagName"] = explainAccountSetFlag(*accountSet.SetFlag)
+		}
+		if accountSet.ClearFlag != nil {
+			fields["ClearFlagName"] = explainAccountSetFlag(*accountSet.ClearFlag)
+		}
+	}
+	times, err := txlib.TxTimes(tx)
+	checkErr(err)
+	for name, t := range times {
+		fields[name+"Time"] = t.String()
+	}
+	if tx.GetHash().IsZero() {
+		delete(fields, "hash")
+	}
+	if outputYAML {
+		outputJSON(fields)
+		return
+	}
+
+	out, err := json.MarshalIndent(fields, "", "  ")
+	checkErr(err)
+	fmt.Println(string(out))
+}
+
+// readTxJSON parses the transaction JSON in the file given as the first
+// argument, or on stdin.
+func readTxJSON(c *cli.Context) data.Transaction {
+	var r io.Reader = os.Stdin
+	if c.Args().First() != "" {
+		f, err := os.Open(c.Args().First())
+		checkErr(err)
+		defer f.Close()
+		r = f
+	}
+	b, err := ioutil.ReadAll(r)
+	checkErr(err)
+	tx, err := txlib.ParseTransactionJSON(b)
+	checkErr(err)
+	return tx
+}
+
+func encode(c *cli.Context) {
+	tx := readTxJSON(c)
+
+	_, raw, err := data.Raw(tx)
+	checkErr(err)
+	if c.GlobalBool("binary") {
+		os.Stdout.Write(raw)
+		return
+	}
+	signingHash, _, err := data.SigningHash(tx)
+	checkErr(err)
+	fmt.Printf("Signing hash: %s\nRaw: %X\n", signingHash, raw)
+}
+
+// signCmd signs a transaction of any type given as JSON. Fields already
+// set in the JSON are kept unless overridden by flags.
+func signCmd(c *cli.Context) {
+	if !haveSigner(c) {
+		fmt.Println("Seed is required")
+		os.Exit(1)
+	}
+	if c.GlobalString("definitions") != "" {
+		signDefinitions(c)
+		return
+	}
+	if len(c.StringSlice("hook-param")) > 0 {
+		fmt.Println("Hook parameters need --definitions for a network with hooks")
+		os.Exit(1)
+	}
+	tx := readTxJSON(c)
+	if c.GlobalIsSet("sequence") {
+		tx.GetBase().Sequence = uint32(c.GlobalInt("sequence"))
+	}
+	if c.Bool("autofill") {
+		autofill(c, tx)
+	}
+	sign(c, tx)
+	outputTx(c, tx)
+}
+
+// hash prints the transaction id of a signed blob, or the signing hash of an
+// unsigned one.
+func hash(c *cli.Context) {
+	tx := decodeTx(readBlob(c))
+	if !tx.GetHash().IsZero() {
+		fmt.Println(tx.GetHash())
+		return
+	}
+	signingHash, _, err := data.SigningHash(tx)
+	checkErr(err)
+	fmt.Println(signingHash)
+}
+
+// verifySignature checks sig over the signing data of tx. The prefix is
+// passed to crypto.Verify along with the message, as ed25519 signs the whole
+// message rather than its hash.
+func verifySignature(pub *data.PublicKey, sig *data.VariableLength, hash data.Hash256, prefix data.HashPrefix, msg []byte) bool {
+	if pub == nil || sig == nil || len(*sig) == 0 {
+		return false
+	}
+	ok, err := crypto.Verify(pub.Bytes(), hash.Bytes(), append(prefix.Bytes(), msg...), sig.Bytes())
+	return err == nil && ok
+}
+
+func pubKeyAccount(pub *data.PublicKey) data.Account {
+	var account data.Account
+	copy(account[:], crypto.Sha256RipeMD160(pub.Bytes()))
+	return account
+}
+
+func validity(ok bool) string {
+	if ok {
+		return "valid"
+	}
+	return "INVALID"
+}
+
+func verify(c *cli.Context) {
+	tx := readTx(c)
+	base := tx.GetBase()
+	allValid := true
+
+	if len(base.Signers) == 0 {
+		hash, msg, err := data.SigningHash(tx)
+		checkErr(err)
+		ok := verifySignature(base.SigningPubKey, base.TxnSignature, hash, tx.SigningPrefix(), msg)
+		allValid = ok
+		fmt.Printf("Signature: %s\n", validity(ok))
+		if base.SigningPubKey != nil && !pubKeyAccount(base.SigningPubKey).Equals(base.Account) {
+			fmt.Printf("Key %s is not the master key of %s, it must be its regular key\n", pubKeyAccount(base.SigningPubKey), base.Account)
+		}
+	}
+
+	// Signers is not part of the signed data, but the library's encoder
+	// only excludes fields named like signatures.
+	signers := base.Signers
+	base.Signers = nil
+	for _, signer := range signers {
+		item := signer.Signer
+		multi := tx.(data.MultiSignable)
+		hash, msg, err := data.MultiSigningHash(multi, item.Account)
+		checkErr(err)
+		ok := verifySignature(item.SigningPubKey, item.TxnSignature, hash, multi.MultiSigningPrefix(), append(msg, item.Account.Bytes()...))
+		allValid = allValid && ok
+		fmt.Printf("Signer %s: %s\n", item.Account, validity(ok))
+		if item.SigningPubKey != nil && !pubKeyAccount(item.SigningPubKey).Equals(item.Account) {
+			fmt.Printf("  Key %s is not the master key of %s, it must be its regular key\n", pubKeyAccount(item.SigningPubKey), item.Account)
+		}
+	}
+
+	if !allValid {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/ripple/websockets"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+// printOffer prints an offer's owner, what it sells, what it wants for it and
+// the price per unit sold.
+func printOffer(prefix string, offer *data.Offer) {
+	fmt.Printf("%s%s %d sells %s for %s at %s\n", prefix, offer.Account, *offer.Sequence, offer.TakerGets, offer.TakerPays, offer.TakerPays.Ratio(*offer.TakerGets))
+}
+
+// printBookChanges prints the offers in the book a transaction created,
+// changed or removed, marked with +, ~ or -.
+func printBookChanges(txm *data.TransactionWithMetaData, gets, pays *data.Asset) {
+	for _, effect := range txm.MetaData.AffectedNodes {
+		_, final := txlib.NodeFields(effect)
+		offer, ok := final.(*data.Offer)
+		if !ok || offer.TakerGets == nil || offer.TakerPays == nil || offer.Account == nil || offer.Sequence == nil {
+			continue
+		}
+		if !gets.Matches(offer.TakerGets) || !pays.Matches(offer.TakerPays) {
+			continue
+		}
+		switch {
+		case effect.CreatedNode != nil:
+			printOffer("+ ", offer)
+		case effect.ModifiedNode != nil:
+			printOffer("~ ", offer)
+		default:
+			printOffer("- ", offer)
+		}
+	}
+}
+
+// book prints the offers selling the first asset for the second. With
+// --stream it keeps printing the offers each validated transaction changes.
+func book(c *cli.Context) {
+	if len(c.Args()) != 2 {
+		fmt.Println("The asset sold and the asset wanted are required")
+		os.Exit(1)
+	}
+	gets, pays := parseAsset(c.Args()[0]), parseAsset(c.Args()[1])
+
+	if !c.Bool("stream") {
+		var result websockets.BookOffersResult
+		checkErr(request(c, "book_offers", map[string]interface{}{
+			"taker_gets":   gets,
+			"taker_pays":   pays,
+			"ledger_index": "validated",
+			"limit":        c.Int("limit"),
+		}, &result))
+		if c.GlobalBool("json") {
+			outputJSON(result)
+			return
+		}
+		fmt.Printf("Ledger: %d\n", result.LedgerSequence)
+		if outputTable {
+			var rows [][]string
+			for i := range result.Offers {
+				offer := &result.Offers[i].Offer
+				rows = append(rows, []string{offer.Account.String(), fmt.Sprint(*offer.Sequence), offer.TakerGets.String(), offer.TakerPays.String(), offer.TakerPays.Ratio(*offer.TakerGets).String()})
+			}
+			printTable([]string{"ACCOUNT", "SEQUENCE", "SELLS", "FOR", "PRICE"}, rows)
+			return
+		}
+		for i := range result.Offers {
+			printOffer("", &result.Offers[i].Offer)
+		}
+		return
+	}
+
+	serveMetrics(c)
+	books := []websockets.OrderBookSubscription{{TakerGets: *gets, TakerPays: *pays, Snapshot: true}}
+	checkErr(subscribe(c, map[string]interface{}{"books": books}, func(msgType string, b []byte) {
+		if c.GlobalBool("json") {
+			printJSON(b)
+			return
+		}
+		switch msgType {
+		case "response":
+			var response struct {
+				Result websockets.SubscribeResult `json:"result"`
+			}
+			checkErr(json.Unmarshal(b, &response))
+			for i := range response.Result.Offers {
+				printOffer("", &response.Result.Offers[i].Offer)
+			}
+		case "transaction":
+			var msg websockets.TransactionStreamMsg
+			checkErr(json.Unmarshal(b, &msg))
+			if msg.Validated {
+				printBookChanges(&msg.Transaction, gets, pays)
+			}
+		}
+	}))
+}
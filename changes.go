@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+// checkDelivered prints the amount a payment actually delivered. Partial
+// payments can deliver far less than their Amount, so crediting a deposit by
+// Amount is a classic mistake and any shortfall gets a prominent warning.
+func checkDelivered(txm *data.TransactionWithMetaData) {
+	payment, ok := txm.Transaction.(*data.Payment)
+	if !ok || !txm.MetaData.TransactionResult.Success() {
+		return
+	}
+	delivered := txm.MetaData.DeliveredAmount
+	if delivered == nil {
+		fmt.Fprintln(os.Stderr, "WARNING: delivered amount unavailable, do not credit this payment by its Amount")
+		return
+	}
+	fmt.Printf("Delivered: %s\n", delivered)
+	if !delivered.SameValue(&payment.Amount) || delivered.Currency != payment.Amount.Currency {
+		fmt.Fprintf(os.Stderr, "WARNING: PARTIAL PAYMENT delivered %s not the Amount of %s\n", delivered, payment.Amount)
+	}
+}
+
+func parseHash(s string) *data.Hash256 {
+	hash, err := data.NewHash256(s)
+	checkErr(err)
+	return hash
+}
+
+func changes(c *cli.Context) {
+	if c.Args().First() == "" {
+		fmt.Println("Transaction hash is required")
+		os.Exit(1)
+	}
+	result, err := newRemote(c).Tx(*parseHash(c.Args().First()))
+	checkErr(err)
+	if !result.Validated {
+		checkErr(fmt.Errorf("Transaction %s is not in a validated ledger", c.Args().First()))
+	}
+	balances, err := txlib.BalanceChanges(&result.TransactionWithMetaData)
+	checkErr(err)
+
+	if c.GlobalBool("json") {
+		outputJSON(map[string]interface{}{
+			"delivered_amount": result.MetaData.DeliveredAmount,
+			"changes":          balances,
+		})
+		return
+	}
+	fmt.Printf("%s %s in ledger %d\n", result.GetType(), result.MetaData.TransactionResult, result.LedgerSequence)
+	checkDelivered(&result.TransactionWithMetaData)
+	var last *data.Account
+	for i, b := range balances {
+		if last == nil || !last.Equals(b.Account) {
+			fmt.Println(b.Account)
+			last = &balances[i].Account
+		}
+		fmt.Printf("  %s\n", b)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+func conditionGenerate(c *cli.Context) {
+	preimage := make([]byte, 32)
+	if c.String("preimage") != "" {
+		var err error
+		preimage, err = hex.DecodeString(c.String("preimage"))
+		checkErr(err)
+	} else {
+		_, err := rand.Read(preimage)
+		checkErr(err)
+	}
+	fmt.Printf("Condition: %X\n", txlib.EncodeCondition(preimage))
+	fmt.Printf("Fulfillment: %X\n", txlib.EncodeFulfillment(preimage))
+}
+
+func conditionVerify(c *cli.Context) {
+	if len(c.Args()) != 2 {
+		fmt.Println("Condition and fulfillment are required")
+		os.Exit(1)
+	}
+	condition, err := hex.DecodeString(c.Args()[0])
+	checkErr(err)
+	fulfillment, err := hex.DecodeString(c.Args()[1])
+	checkErr(err)
+	preimage, err := txlib.DecodeFulfillment(fulfillment)
+	checkErr(err)
+	ok := bytes.Equal(txlib.EncodeCondition(preimage), condition)
+	fmt.Printf("Fulfillment: %s\n", validity(ok))
+	if !ok {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/codegangsta/cli"
+)
+
+// Default servers for --network
+var networkServers = map[string]string{
+	"main": "wss://s-east.ripple.com:443",
+	"test": "wss://s.altnet.rippletest.net:51233",
+	"dev":  "wss://s.devnet.rippletest.net:51233",
+}
+
+// configPath returns the --config file, or else tx/config.toml in the user
+// config directory if it exists.
+func configPath(c *cli.Context) (string, error) {
+	if path := c.GlobalString("config"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", nil
+	}
+	path := filepath.Join(dir, "tx", "config.toml")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return "", nil
+	}
+	return path, nil
+}
+
+// loadConfig sets global flags from the config file. Settings are named
+// like the flags, for example
+//
+//	server = "wss://s.altnet.rippletest.net:51233"
+//	fee = 12
+//	key-name = "hot"
+//	json = true
+//
+// Named profiles hold settings for one network, chosen with --profile or
+// the profile setting. They override the settings outside any profile.
+//
+//	[profiles.prod]
+//	server = ["wss://s1.ripple.com:443", "wss://s2.ripple.com:443"]
+//	network-id = 0
+//	key-name = "treasury"
+//	max-fee = 1000
+//
+// Flags given on the command line or in TX_ environment variables win.
+func loadConfig(c *cli.Context) error {
+	path, err := configPath(c)
+	if err != nil || path == "" {
+		if c.GlobalString("profile") != "" {
+			return fmt.Errorf("No config file for --profile %s", c.GlobalString("profile"))
+		}
+		return err
+	}
+	var config map[string]interface{}
+	if _, err := toml.DecodeFile(path, &config); err != nil {
+		return err
+	}
+	profiles, _ := config["profiles"].(map[string]interface{})
+	delete(config, "profiles")
+
+	name := c.GlobalString("profile")
+	if name == "" {
+		name, _ = config["profile"].(string)
+	}
+	if name != "" {
+		profile, ok := profiles[name].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("No profile %s in %s", name, path)
+		}
+		if err := applySettings(c, path, profile); err != nil {
+			return err
+		}
+	}
+	return applySettings(c, path, config)
+}
+
+// configProfile returns the settings of the named profile in the config
+// file.
+func configProfile(c *cli.Context, name string) (map[string]interface{}, error) {
+	path, err := configPath(c)
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return nil, fmt.Errorf("No config file for profile %s", name)
+	}
+	var config map[string]interface{}
+	if _, err := toml.DecodeFile(path, &config); err != nil {
+		return nil, err
+	}
+	profiles, _ := config["profiles"].(map[string]interface{})
+	profile, ok := profiles[name].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("No profile %s in %s", name, path)
+	}
+	return profile, nil
+}
+
+func applySettings(c *cli.Context, path string, settings map[string]interface{}) error {
+	known := make(map[string]bool)
+	for _, name := range c.GlobalFlagNames() {
+		known[name] = name != "config"
+	}
+	var names []string
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !known[name] {
+			return fmt.Errorf("Unknown setting %s in %s", name, path)
+		}
+		if c.GlobalIsSet(name) {
+			continue
+		}
+		switch value := settings[name].(type) {
+		case string, int64, float64, bool:
+			if err := c.GlobalSet(name, fmt.Sprint(value)); err != nil {
+				return fmt.Errorf("Bad setting %s in %s: %s", name, path, err)
+			}
+		case []interface{}:
+			// Lists are given to flags as comma separated values.
+			var items []string
+			for _, item := range value {
+				items = append(items, fmt.Sprint(item))
+			}
+			if err := c.GlobalSet(name, strings.Join(items, ",")); err != nil {
+				return fmt.Errorf("Bad setting %s in %s: %s", name, path, err)
+			}
+		default:
+			return fmt.Errorf("Setting %s in %s must be a string, number, boolean or list", name, path)
+		}
+	}
+	return nil
+}
+
+// setNetwork points --server at the --network's server, or a local
+// standalone rippled with --standalone, unless a server is given.
+func setNetwork(c *cli.Context) error {
+	network := c.GlobalString("network")
+	if c.GlobalIsSet("server") {
+		return nil
+	}
+	if c.GlobalBool("standalone") {
+		if network != "" {
+			return fmt.Errorf("--standalone and --network %s cannot both be used", network)
+		}
+		return c.GlobalSet("server", standaloneServer)
+	}
+	if network == "" {
+		return nil
+	}
+	server, ok := networkServers[network]
+	if !ok {
+		return fmt.Errorf("Unknown network %s, use main, test or dev", network)
+	}
+	return c.GlobalSet("server", server)
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+// loadDefinitions reads the --definitions file, or fetches the server's
+// definitions when it is "server".
+func loadDefinitions(c *cli.Context) *txlib.Definitions {
+	var b []byte
+	if c.GlobalString("definitions") == "server" {
+		var raw json.RawMessage
+		checkErr(request(c, "server_definitions", nil, &raw))
+		b = raw
+	} else {
+		var err error
+		b, err = ioutil.ReadFile(c.GlobalString("definitions"))
+		checkErr(err)
+	}
+	defs, err := txlib.ParseDefinitions(b)
+	checkErr(err)
+	return defs
+}
+
+// readTxMap reads a transaction as JSON from the file argument or stdin,
+// keeping its numbers exact.
+func readTxMap(c *cli.Context) map[string]interface{} {
+	var r io.Reader = os.Stdin
+	if c.Args().First() != "" {
+		f, err := os.Open(c.Args().First())
+		checkErr(err)
+		defer f.Close()
+		r = f
+	}
+	b, err := ioutil.ReadAll(r)
+	checkErr(err)
+	b, err = txlib.ConvertTimes(b)
+	checkErr(err)
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var tx map[string]interface{}
+	checkErr(d.Decode(&tx))
+	return tx
+}
+
+func uintField(tx map[string]interface{}, name string) (uint64, bool) {
+	switch v := tx[name].(type) {
+	case json.Number:
+		n, err := strconv.ParseUint(v.String(), 10, 64)
+		checkErr(err)
+		return n, true
+	case string:
+		n, err := strconv.ParseUint(v, 10, 64)
+		checkErr(err)
+		return n, true
+	}
+	return 0, false
+}
+
+func setUintField(tx map[string]interface{}, name string, n uint64) {
+	tx[name] = json.Number(strconv.FormatUint(n, 10))
+}
+
+// prepareTxMap fills in the fields Prepare and autofill would for a
+// transaction the data package cannot hold. Fields from the flags win, and
+// with --autofill missing ones are fetched from the server.
+func prepareTxMap(c *cli.Context, defs *txlib.Definitions, tx map[string]interface{}) {
+	if c.GlobalString("account") != "" || tx["Account"] == nil {
+		account := keyAccount(c)
+		if account == nil {
+			checkErr(fmt.Errorf("An account or key is required"))
+		}
+		tx["Account"] = account.String()
+	}
+	_, haveSequence := uintField(tx, "Sequence")
+	switch {
+	case c.GlobalIsSet("sequence"):
+		setUintField(tx, "Sequence", uint64(c.GlobalInt("sequence")))
+	case !haveSequence && c.Bool("autofill"):
+		// account_info rather than the library's AccountInfo, which may not
+		// know the network's account fields.
+		var info struct {
+			AccountData struct {
+				Sequence uint32 `json:"Sequence"`
+			} `json:"account_data"`
+			LedgerCurrentIndex uint32 `json:"ledger_current_index"`
+		}
+		checkErr(request(c, "account_info", map[string]interface{}{"account": tx["Account"], "ledger_index": "current"}, &info))
+		setUintField(tx, "Sequence", uint64(info.AccountData.Sequence))
+		if tx["LastLedgerSequence"] == nil && !c.GlobalIsSet("lastledger") {
+			setUintField(tx, "LastLedgerSequence", uint64(info.LedgerCurrentIndex+autofillLedgers))
+		}
+	case !haveSequence:
+		setUintField(tx, "Sequence", 0)
+	}
+	if c.GlobalInt("lastledger") > 0 {
+		setUintField(tx, "LastLedgerSequence", uint64(c.GlobalInt("lastledger")))
+	}
+	// Networks past 1024 need their id in every transaction.
+	if c.GlobalInt("network-id") > 1024 {
+		setUintField(tx, "NetworkID", uint64(c.GlobalInt("network-id")))
+	}
+	flags, _ := uintField(tx, "Flags")
+	if !c.GlobalBool("no-canonical") && (key == nil || !txlib.IsEd25519(key, keySequence)) {
+		flags |= 0x80000000
+	}
+	setUintField(tx, "Flags", flags)
+
+	// The fee comes last, a hook fee depends on the rest of the transaction.
+	// On a network with hooks any transaction may run them and only the
+	// server knows what they cost, so unless --fee is given it is fetched.
+	switch {
+	case c.GlobalIsSet("fee"):
+		tx["Fee"] = strconv.Itoa(c.GlobalInt("fee"))
+	case defs.HasHooks():
+		tx["Fee"] = strconv.FormatInt(hookFeeDrops(c, defs, tx), 10)
+	case c.GlobalString("fee-strategy") == "server" || (tx["Fee"] == nil && c.Bool("autofill")):
+		drops, err := openLedgerDrops(c)
+		checkErr(err)
+		tx["Fee"] = strconv.FormatInt(drops, 10)
+	case tx["Fee"] == nil:
+		tx["Fee"] = strconv.Itoa(txlib.DefaultFee)
+	}
+	if max := c.GlobalInt("max-fee"); max > 0 {
+		fee, err := strconv.Atoi(fmt.Sprint(tx["Fee"]))
+		checkErr(err)
+		if fee > max {
+			checkErr(fmt.Errorf("Fee of %d drops is above --max-fee %d", fee, max))
+		}
+	}
+}
+
+// hookFeeDrops asks the server for the fee of the transaction itself, as
+// Xahau's fee command does when given a tx_blob. It counts the hooks the
+// transaction will run and its HookParameters, which the fee for a plain
+// transaction does not, and underpaying them fails with telINSUF_FEE_P or a
+// tem code.
+func hookFeeDrops(c *cli.Context, defs *txlib.Definitions, tx map[string]interface{}) int64 {
+	unsigned := make(map[string]interface{}, len(tx))
+	for name, v := range tx {
+		unsigned[name] = v
+	}
+	unsigned["Fee"] = "0"
+	if key != nil {
+		unsigned["SigningPubKey"] = fmt.Sprintf("%X", key.Public(keySequence))
+	} else if unsigned["SigningPubKey"] == nil {
+		unsigned["SigningPubKey"] = ""
+	}
+	delete(unsigned, "TxnSignature")
+	blob, err := defs.Encode(unsigned, false)
+	checkErr(err)
+	var fee feeResult
+	checkErr(request(c, "fee", map[string]interface{}{"tx_blob": fmt.Sprintf("%X", blob)}, &fee))
+	base, err := strconv.ParseInt(fee.Drops.BaseFee, 10, 64)
+	checkErr(err)
+	open, err := strconv.ParseInt(fee.Drops.OpenLedgerFee, 10, 64)
+	checkErr(err)
+	// The open ledger fee is for a reference transaction, under load it can
+	// be the larger of the two.
+	if open > base {
+		return open
+	}
+	return base
+}
+
+// signDefinitions signs a transaction given as JSON with the --definitions
+// encoder, for fields and types the data package does not know.
+func signDefinitions(c *cli.Context) {
+	defs := loadDefinitions(c)
+	tx := readTxMap(c)
+	checkErr(txlib.CheckEmitted(tx))
+	checkErr(defs.AddHookParameters(tx, c.StringSlice("hook-param")))
+	prepareTxMap(c, defs, tx)
+
+	if c.GlobalBool("unsigned") {
+		if c.GlobalBool("submit") {
+			checkErr(fmt.Errorf("Unsigned transactions cannot be submitted"))
+		}
+		if key != nil {
+			tx["SigningPubKey"] = fmt.Sprintf("%X", key.Public(keySequence))
+		}
+		raw, err := defs.Encode(tx, false)
+		checkErr(err)
+		signingHash, err := defs.SigningHash(tx)
+		checkErr(err)
+		outputTxMap(c, tx, signingHash, raw)
+		return
+	}
+	hash, raw, err := defs.SignJSON(tx, key, keySequence)
+	checkErr(err)
+	signedTotal.Inc()
+	tx["hash"] = hash.String()
+	outputTxMap(c, tx, hash, raw)
+}
+
+// outputTxMap prints a transaction encoded with the definitions as
+// outputTx prints one sign encoded, and submits it with --submit. The hash
+// is the signing hash of an unsigned transaction.
+func outputTxMap(c *cli.Context, tx map[string]interface{}, hash data.Hash256, raw []byte) {
+	b, err := json.Marshal(tx)
+	checkErr(err)
+	label := "Hash"
+	if c.GlobalBool("unsigned") {
+		label = "Signing hash"
+	}
+	// full-json submits as it builds its object.
+	submit := c.GlobalBool("submit") && c.GlobalString("output") != "full-json"
+	if c.GlobalString("output") == "full-json" {
+		out := fullOutput{TxBlob: fmt.Sprintf("%X", raw), Tx: b}
+		if c.GlobalBool("unsigned") {
+			out.SigningHash = &hash
+		} else {
+			out.Hash = &hash
+		}
+		if c.GlobalBool("submit") {
+			result, err := submitBlob(c, raw)
+			checkErr(err)
+			lastLedger, _ := uintField(tx, "LastLedgerSequence")
+			out.await(c, hash, uint32(lastLedger), result)
+		}
+		b, err = json.Marshal(out)
+		checkErr(err)
+	}
+
+	switch {
+	case c.GlobalString("out") != "":
+		doc := formatJSON(b)
+		if c.GlobalBool("binary") {
+			doc = raw
+		}
+		checkErr(writeFileAtomic(c.GlobalString("out"), doc))
+		if !c.GlobalBool("quiet") {
+			fmt.Printf("%s: %s\nWritten to: %s\n", label, hash, c.GlobalString("out"))
+		}
+	case c.GlobalBool("quiet"):
+	case c.GlobalString("output") == "full-json":
+		printJSON(b)
+	default:
+		switch {
+		case c.GlobalBool("json"):
+			printJSON(b)
+		case c.GlobalBool("binary"):
+			os.Stdout.Write(raw)
+		default:
+			fmt.Printf("%s: %s\nRaw: %X\n", label, hash, raw)
+			printJSON(b)
+		}
+		printQR(c, fmt.Sprintf("%X", raw))
+	}
+
+	if submit {
+		result, err := submitBlob(c, raw)
+		checkErr(err)
+		printSubmitResult(c, result)
+	}
+	if c.GlobalBool("quiet") {
+		// Only the hash, once any submission has been accepted.
+		fmt.Println(hash)
+	}
+}
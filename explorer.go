@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+)
+
+// Default explorers by --network
+var explorers = map[string]string{
+	"main": "https://livenet.xrpl.org",
+	"test": "https://testnet.xrpl.org",
+	"dev":  "https://devnet.xrpl.org",
+}
+
+// Transaction fields naming the accounts it involves
+var accountFields = []string{"Account", "Destination", "Owner", "RegularKey", "Authorize"}
+
+// explorerURLs returns the --explorer-tx-url and --explorer-account-url
+// templates, defaulting to the explorer for the --network.
+func explorerURLs(c *cli.Context) (string, string) {
+	base, ok := explorers[c.GlobalString("network")]
+	if !ok {
+		base = explorers["main"]
+	}
+	txURL, accountURL := c.GlobalString("explorer-tx-url"), c.GlobalString("explorer-account-url")
+	if txURL == "" {
+		txURL = base + "/transactions/{hash}"
+	}
+	if accountURL == "" {
+		accountURL = base + "/accounts/{account}"
+	}
+	return txURL, accountURL
+}
+
+// txAccounts returns the distinct accounts a transaction names.
+func txAccounts(tx data.Transaction) []string {
+	b, err := json.Marshal(tx)
+	checkErr(err)
+	var fields map[string]interface{}
+	checkErr(json.Unmarshal(b, &fields))
+	var accounts []string
+	seen := make(map[string]bool)
+	for _, name := range accountFields {
+		if account, ok := fields[name].(string); ok && !seen[account] {
+			seen[account] = true
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts
+}
+
+// printExplorerLinks prints explorer links for a signed transaction and the
+// accounts it involves, if --explorer is set.
+func printExplorerLinks(c *cli.Context, tx data.Transaction) {
+	if !c.GlobalBool("explorer") {
+		return
+	}
+	txURL, accountURL := explorerURLs(c)
+	fmt.Printf("Explorer: %s\n", strings.Replace(txURL, "{hash}", tx.GetHash().String(), -1))
+	for _, account := range txAccounts(tx) {
+		fmt.Printf("Explorer %s: %s\n", account, strings.Replace(accountURL, "{account}", account, -1))
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+)
+
+// exportAccount returns each validated transaction of the account in the
+// ledger range, oldest first, as a line of JSON with its metadata and
+// ledger index. A bound of -1 is the oldest or newest ledger the server has.
+func exportAccount(c *cli.Context, account data.Account, min, max int) ([]byte, int) {
+	var buf bytes.Buffer
+	count := 0
+	var marker interface{}
+	for {
+		params := map[string]interface{}{
+			"account":          account.String(),
+			"ledger_index_min": min,
+			"ledger_index_max": max,
+			"forward":          true,
+			"limit":            200,
+		}
+		if marker != nil {
+			params["marker"] = marker
+		}
+		var result struct {
+			Transactions []json.RawMessage `json:"transactions"`
+			Marker       interface{}       `json:"marker"`
+		}
+		checkErr(request(c, "account_tx", params, &result))
+		for _, t := range result.Transactions {
+			var line bytes.Buffer
+			checkErr(json.Compact(&line, t))
+			buf.Write(line.Bytes())
+			buf.WriteByte('\n')
+			count++
+		}
+		if marker = result.Marker; marker == nil {
+			return buf.Bytes(), count
+		}
+	}
+}
+
+// export writes the transactions touching each account in a ledger range to
+// account.ndjson in --dir, one JSON object per line, for archiving.
+func export(c *cli.Context) {
+	if len(c.Args()) == 0 {
+		fmt.Println("At least one account is required")
+		os.Exit(1)
+	}
+	min, max := c.Int("from"), c.Int("to")
+	if min >= 0 && max >= 0 && min > max {
+		checkErr(fmt.Errorf("--from %d is after --to %d", min, max))
+	}
+	checkErr(os.MkdirAll(c.String("dir"), 0755))
+	for _, arg := range c.Args() {
+		account := parseAccount(arg)
+		b, count := exportAccount(c, *account, min, max)
+		path := filepath.Join(c.String("dir"), account.String()+".ndjson")
+		checkErr(writeFileAtomic(path, b))
+		fmt.Printf("%s: %d transactions\n", path, count)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codegangsta/cli"
+)
+
+func printFees(fee *feeResult) {
+	fmt.Printf("%d Open ledger fee: %s Minimum: %s Median: %s Base: %s drops Ledger: %s/%s Queue: %s/%s\n",
+		fee.LedgerCurrentIndex, fee.Drops.OpenLedgerFee, fee.Drops.MinimumFee, fee.Drops.MedianFee, fee.Drops.BaseFee,
+		fee.CurrentLedgerSize, fee.ExpectedLedgerSize, fee.CurrentQueueSize, fee.MaxQueueSize)
+}
+
+// fees prints the fee needed to get into the open ledger and how full the
+// ledger and queue are. With --stream it prints them again as each ledger
+// closes, to watch fees escalate under load.
+func fees(c *cli.Context) {
+	if !c.Bool("stream") {
+		var fee feeResult
+		checkErr(request(c, "fee", nil, &fee))
+		if c.GlobalBool("json") {
+			outputJSON(fee)
+			return
+		}
+		printFees(&fee)
+		return
+	}
+	checkErr(subscribe(c, map[string]interface{}{"streams": []string{"ledger"}}, func(msgType string, b []byte) {
+		if msgType != "ledgerClosed" && msgType != "response" {
+			return
+		}
+		var fee feeResult
+		if err := request(c, "fee", nil, &fee); err != nil {
+			// Keep watching, a busy server may fail the odd request.
+			fmt.Fprintf(os.Stderr, "Warning: fee: %s\n", err)
+			return
+		}
+		if c.GlobalBool("json") {
+			outputJSON(fee)
+			return
+		}
+		printFees(&fee)
+	}))
+}
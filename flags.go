@@ -0,0 +1,224 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+// The library has no constants for the Deep Freeze TrustSet flags.
+const (
+	txSetDeepFreeze   data.TransactionFlag = 0x00400000
+	txClearDeepFreeze data.TransactionFlag = 0x00800000
+)
+
+type flagName struct {
+	Flag uint32
+	Name string
+}
+
+// Transaction flags by TransactionType. The universal flags apply to all.
+var txFlags = map[string][]flagName{
+	"Payment": {
+		{uint32(data.TxNoDirectRipple), "tfNoRippleDirect"},
+		{uint32(data.TxPartialPayment), "tfPartialPayment"},
+		{uint32(data.TxLimitQuality), "tfLimitQuality"},
+	},
+	"AccountSet": {
+		{uint32(data.TxRequireDestTag), "tfRequireDestTag"},
+		{uint32(data.TxOptionalDestTag), "tfOptionalDestTag"},
+		{uint32(data.TxRequireAuth), "tfRequireAuth"},
+		{uint32(data.TxOptionalAuth), "tfOptionalAuth"},
+		{uint32(data.TxDisallowXRP), "tfDisallowXRP"},
+		{uint32(data.TxAllowXRP), "tfAllowXRP"},
+	},
+	"OfferCreate": {
+		{uint32(data.TxPassive), "tfPassive"},
+		{uint32(data.TxImmediateOrCancel), "tfImmediateOrCancel"},
+		{uint32(data.TxFillOrKill), "tfFillOrKill"},
+		{uint32(data.TxSell), "tfSell"},
+	},
+	"TrustSet": {
+		{uint32(data.TxSetAuth), "tfSetfAuth"},
+		{uint32(data.TxSetNoRipple), "tfSetNoRipple"},
+		{uint32(data.TxClearNoRipple), "tfClearNoRipple"},
+		{uint32(data.TxSetFreeze), "tfSetFreeze"},
+		{uint32(data.TxClearFreeze), "tfClearFreeze"},
+		{uint32(txSetDeepFreeze), "tfSetDeepFreeze"},
+		{uint32(txClearDeepFreeze), "tfClearDeepFreeze"},
+	},
+	"PaymentChannelClaim": {
+		{uint32(data.TxRenew), "tfRenew"},
+		{uint32(data.TxClose), "tfClose"},
+	},
+	"EnableAmendment": {
+		{uint32(data.TxGotMajority), "tfGotMajority"},
+		{uint32(data.TxLostMajority), "tfLostMajority"},
+	},
+	"NFTokenMint": {
+		{nftBurnable, "tfBurnable"},
+		{nftOnlyXRP, "tfOnlyXRP"},
+		{nftTrustLine, "tfTrustLine"},
+		{txlib.NFTokenTransferable, "tfTransferable"},
+	},
+	"NFTokenCreateOffer": {
+		{nftSellOffer, "tfSellNFToken"},
+	},
+	"AMMDeposit": {
+		{0x00010000, "tfLPToken"},
+		{0x00080000, "tfSingleAsset"},
+		{0x00100000, "tfTwoAsset"},
+		{0x00200000, "tfOneAssetLPToken"},
+		{0x00400000, "tfLimitLPToken"},
+		{0x00800000, "tfTwoAssetIfEmpty"},
+	},
+	"AMMWithdraw": {
+		{0x00010000, "tfLPToken"},
+		{0x00020000, "tfWithdrawAll"},
+		{0x00040000, "tfOneAssetWithdrawAll"},
+		{0x00080000, "tfSingleAsset"},
+		{0x00100000, "tfTwoAsset"},
+		{0x00200000, "tfOneAssetLPToken"},
+		{0x00400000, "tfLimitLPToken"},
+	},
+}
+
+var universalTxFlags = []flagName{
+	{uint32(data.TxCanonicalSignature), "tfFullyCanonicalSig"},
+}
+
+// AccountSet SetFlag and ClearFlag values. These are not bits.
+var accountSetFlags = []flagName{
+	{1, "asfRequireDest"},
+	{2, "asfRequireAuth"},
+	{3, "asfDisallowXRP"},
+	{4, "asfDisableMaster"},
+	{5, "asfAccountTxnID"},
+	{6, "asfNoFreeze"},
+	{7, "asfGlobalFreeze"},
+	{8, "asfDefaultRipple"},
+	{9, "asfDepositAuth"},
+	{10, "asfAuthorizedNFTokenMinter"},
+	{12, "asfDisallowIncomingNFTokenOffer"},
+	{13, "asfDisallowIncomingCheck"},
+	{14, "asfDisallowIncomingPayChan"},
+	{15, "asfDisallowIncomingTrustline"},
+	{16, "asfAllowTrustLineClawback"},
+}
+
+// Ledger entry flags by LedgerEntryType.
+var leFlags = map[string][]flagName{
+	"AccountRoot": {
+		{0x00010000, "lsfPasswordSpent"},
+		{0x00020000, "lsfRequireDestTag"},
+		{0x00040000, "lsfRequireAuth"},
+		{0x00080000, "lsfDisallowXRP"},
+		{0x00100000, "lsfDisableMaster"},
+		{0x00200000, "lsfNoFreeze"},
+		{0x00400000, "lsfGlobalFreeze"},
+		{0x00800000, "lsfDefaultRipple"},
+		{0x01000000, "lsfDepositAuth"},
+		{0x02000000, "lsfAMM"},
+		{0x04000000, "lsfDisallowIncomingNFTokenOffer"},
+		{0x08000000, "lsfDisallowIncomingCheck"},
+		{0x10000000, "lsfDisallowIncomingPayChan"},
+		{0x20000000, "lsfDisallowIncomingTrustline"},
+		{0x80000000, "lsfAllowTrustLineClawback"},
+	},
+	"Offer": {
+		{0x00010000, "lsfPassive"},
+		{0x00020000, "lsfSell"},
+	},
+	"RippleState": {
+		{0x00010000, "lsfLowReserve"},
+		{0x00020000, "lsfHighReserve"},
+		{0x00040000, "lsfLowAuth"},
+		{0x00080000, "lsfHighAuth"},
+		{0x00100000, "lsfLowNoRipple"},
+		{0x00200000, "lsfHighNoRipple"},
+		{0x00400000, "lsfLowFreeze"},
+		{0x00800000, "lsfHighFreeze"},
+		{0x01000000, "lsfAMMNode"},
+		{0x02000000, "lsfLowDeepFreeze"},
+		{0x04000000, "lsfHighDeepFreeze"},
+	},
+	"SignerList": {
+		{0x00010000, "lsfOneOwnerCount"},
+	},
+	"DirectoryNode": {
+		{0x00000001, "lsfNFTokenBuyOffers"},
+		{0x00000002, "lsfNFTokenSellOffers"},
+	},
+	"NFTokenOffer": {
+		{0x00000001, "lsfSellNFToken"},
+	},
+}
+
+// explainFlags names each bit of flags. Bits with no name are returned as a
+// single hex value.
+func explainFlags(names []flagName, flags uint32) []string {
+	var explained []string
+	for _, n := range names {
+		if flags&n.Flag == n.Flag {
+			explained = append(explained, n.Name)
+			flags &^= n.Flag
+		}
+	}
+	if flags != 0 {
+		explained = append(explained, fmt.Sprintf("0x%08X", flags))
+	}
+	return explained
+}
+
+func explainTxFlags(typ string, flags uint32) []string {
+	return explainFlags(append(universalTxFlags, txFlags[typ]...), flags)
+}
+
+func explainAccountSetFlag(value uint32) string {
+	for _, n := range accountSetFlags {
+		if n.Flag == value {
+			return n.Name
+		}
+	}
+	return strconv.FormatUint(uint64(value), 10)
+}
+
+// parseAccountSetFlag reads an AccountSet flag as a value or a name, with or
+// without the asf prefix and in any case.
+func parseAccountSetFlag(s string) (uint32, error) {
+	if value, err := strconv.ParseUint(s, 10, 32); err == nil {
+		return uint32(value), nil
+	}
+	for _, n := range accountSetFlags {
+		if strings.EqualFold(n.Name, s) || strings.EqualFold(strings.TrimPrefix(n.Name, "asf"), s) {
+			return n.Flag, nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown AccountSet flag: %s", s)
+}
+
+func explainFlagsCmd(c *cli.Context) {
+	if len(c.Args()) != 2 {
+		fmt.Println("A transaction or ledger entry type, and a flags value are required")
+		os.Exit(1)
+	}
+	typ := c.Args()[0]
+	flags, err := strconv.ParseUint(c.Args()[1], 0, 32)
+	checkErr(err)
+
+	switch {
+	case typ == "asf":
+		fmt.Println(explainAccountSetFlag(uint32(flags)))
+	case leFlags[typ] != nil:
+		fmt.Println(strings.Join(explainFlags(leFlags[typ], uint32(flags)), "\n"))
+	case txlib.TxFactory(typ) != nil || txFlags[typ] != nil:
+		fmt.Println(strings.Join(explainTxFlags(typ, uint32(flags)), "\n"))
+	default:
+		checkErr(fmt.Errorf("Unknown type: %s", typ))
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/ripple/websockets"
+	"github.com/rubblelabs/tx/signerpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// grpcSigner is the gRPC face of serve's signer.
+type grpcSigner struct {
+	signerpb.UnimplementedSignerServer
+	*signer
+}
+
+// authorize checks the bearer token in the authorization metadata.
+func (g *grpcSigner) authorize(ctx context.Context) error {
+	if g.token == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		if g.authorized(v) {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "Missing or wrong bearer token")
+}
+
+func (g *grpcSigner) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := g.authorize(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (g *grpcSigner) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := g.authorize(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+func (g *grpcSigner) Sign(ctx context.Context, req *signerpb.SignRequest) (*signerpb.SignedTransaction, error) {
+	tx, err := g.signJSON([]byte(req.TxJson))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	_, raw, err := data.Raw(tx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	b, err := json.Marshal(tx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &signerpb.SignedTransaction{Hash: tx.GetHash().String(), TxBlob: raw, TxJson: string(b)}, nil
+}
+
+// submitOne signs if needed and submits one streamed transaction. Failures
+// are reported in the result so the stream carries on.
+func (g *grpcSigner) submitOne(req *signerpb.SubmitRequest) *signerpb.SubmitResult {
+	var tx data.Transaction
+	var err error
+	if req.GetTxBlob() != nil {
+		tx, err = data.ReadTransaction(bytes.NewReader(req.GetTxBlob()))
+	} else {
+		tx, err = g.signJSON([]byte(req.GetTxJson()))
+	}
+	if err != nil {
+		return &signerpb.SubmitResult{Error: err.Error()}
+	}
+	hash, _, err := data.Raw(tx)
+	if err != nil {
+		return &signerpb.SubmitResult{Error: err.Error()}
+	}
+	result, err := g.send(tx)
+	if err != nil {
+		return &signerpb.SubmitResult{Hash: hash.String(), Error: err.Error()}
+	}
+	return &signerpb.SubmitResult{
+		Hash:                hash.String(),
+		EngineResult:        result.EngineResult.String(),
+		EngineResultMessage: result.EngineResultMessage,
+	}
+}
+
+func (g *grpcSigner) Submit(stream grpc.BidiStreamingServer[signerpb.SubmitRequest, signerpb.SubmitResult]) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(g.submitOne(req)); err != nil {
+			return err
+		}
+	}
+}
+
+func (g *grpcSigner) Status(ctx context.Context, req *signerpb.StatusRequest) (*signerpb.StatusResult, error) {
+	hash, err := data.NewHash256(req.Hash)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	r, err := websockets.NewRemote(server(g.c))
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	defer r.Close()
+	result, err := r.Tx(*hash)
+	if e, ok := err.(*websockets.CommandError); ok && e.Name == "txnNotFound" {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	b, err := json.Marshal(result.Transaction)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	s := &signerpb.StatusResult{
+		Hash:        hash.String(),
+		Validated:   result.Validated,
+		Result:      result.MetaData.TransactionResult.String(),
+		LedgerIndex: result.LedgerSequence,
+		TxJson:      string(b),
+	}
+	if delivered := result.MetaData.DeliveredAmount; delivered != nil {
+		s.DeliveredAmount = delivered.String()
+	}
+	return s, nil
+}
+
+// serveGRPC serves the Signer service on addr.
+func serveGRPC(s *signer, addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("gRPC: %s", err)
+	}
+	g := &grpcSigner{signer: s}
+	server := grpc.NewServer(grpc.UnaryInterceptor(g.unary), grpc.StreamInterceptor(g.stream))
+	signerpb.RegisterSignerServer(server, g)
+	return server.Serve(l)
+}
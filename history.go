@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+// historyEntry is one transaction of an account's history. The
+// counterparty is the first other account the transaction names.
+type historyEntry struct {
+	Date            time.Time              `json:"date"`
+	Hash            data.Hash256           `json:"hash"`
+	Type            string                 `json:"type"`
+	Counterparty    string                 `json:"counterparty,omitempty"`
+	Amount          *data.Amount           `json:"amount,omitempty"`
+	Fee             data.Value             `json:"fee"`
+	DeliveredAmount *data.Amount           `json:"delivered_amount,omitempty"`
+	Result          data.TransactionResult `json:"result"`
+}
+
+type accountTxResult struct {
+	Transactions []struct {
+		Meta json.RawMessage `json:"meta"`
+		Tx   json.RawMessage `json:"tx"`
+	} `json:"transactions"`
+	Marker interface{} `json:"marker"`
+}
+
+func newHistoryEntry(account data.Account, rawTx, rawMeta json.RawMessage) (*historyEntry, error) {
+	tx, err := txlib.ParseTransactionJSON(rawTx)
+	if err != nil {
+		return nil, err
+	}
+	var fields struct {
+		Amount *data.Amount `json:"Amount"`
+		Date   uint32       `json:"date"`
+	}
+	if err := json.Unmarshal(rawTx, &fields); err != nil {
+		return nil, err
+	}
+	var meta struct {
+		TransactionResult data.TransactionResult `json:"TransactionResult"`
+		DeliveredAmount   json.RawMessage        `json:"delivered_amount"`
+	}
+	if err := json.Unmarshal(rawMeta, &meta); err != nil {
+		return nil, err
+	}
+	entry := &historyEntry{
+		Date:   time.Unix(int64(fields.Date)+txlib.RippleEpoch, 0).UTC(),
+		Hash:   *tx.GetHash(),
+		Type:   tx.GetType(),
+		Amount: fields.Amount,
+		Fee:    tx.GetBase().Fee,
+		Result: meta.TransactionResult,
+	}
+	// Old ledgers give "unavailable" rather than an amount.
+	var delivered data.Amount
+	if len(meta.DeliveredAmount) > 0 && json.Unmarshal(meta.DeliveredAmount, &delivered) == nil {
+		entry.DeliveredAmount = &delivered
+	}
+	for _, other := range txAccounts(tx) {
+		if other != account.String() {
+			entry.Counterparty = other
+			break
+		}
+	}
+	return entry, nil
+}
+
+// fetchHistory returns up to limit of the account's validated
+// transactions, newest first, or all of them if limit is 0.
+func fetchHistory(c *cli.Context, account data.Account, limit int) []*historyEntry {
+	var entries []*historyEntry
+	var marker interface{}
+	for {
+		params := map[string]interface{}{
+			"account":          account.String(),
+			"ledger_index_min": -1,
+			"ledger_index_max": -1,
+			"limit":            200,
+		}
+		if marker != nil {
+			params["marker"] = marker
+		}
+		var result accountTxResult
+		checkErr(request(c, "account_tx", params, &result))
+		for _, t := range result.Transactions {
+			entry, err := newHistoryEntry(account, t.Tx, t.Meta)
+			checkErr(err)
+			entries = append(entries, entry)
+			if limit > 0 && len(entries) == limit {
+				return entries
+			}
+		}
+		if marker = result.Marker; marker == nil {
+			return entries
+		}
+	}
+}
+
+func amountString(amount *data.Amount) string {
+	if amount == nil {
+		return ""
+	}
+	return amount.String()
+}
+
+// writeHistoryCSV writes the entries with a header row, for spreadsheets and
+// accounting systems.
+func writeHistoryCSV(entries []*historyEntry) {
+	w := csv.NewWriter(os.Stdout)
+	checkErr(w.Write([]string{"date", "type", "counterparty", "amount", "fee", "delivered_amount", "result", "hash"}))
+	for _, e := range entries {
+		checkErr(w.Write([]string{
+			e.Date.Format(time.RFC3339),
+			e.Type,
+			e.Counterparty,
+			amountString(e.Amount),
+			e.Fee.String(),
+			amountString(e.DeliveredAmount),
+			e.Result.String(),
+			e.Hash.String(),
+		}))
+	}
+	w.Flush()
+	checkErr(w.Error())
+}
+
+// history prints an account's validated transactions, newest first.
+func history(c *cli.Context) {
+	account := argAccount(c)
+	if account == nil {
+		fmt.Println("Account or seed is required")
+		os.Exit(1)
+	}
+	entries := fetchHistory(c, *account, c.Int("limit"))
+
+	switch {
+	case c.Bool("csv"):
+		writeHistoryCSV(entries)
+	case c.GlobalBool("json"):
+		outputJSON(entries)
+	case outputTable:
+		var rows [][]string
+		for _, e := range entries {
+			rows = append(rows, []string{e.Date.Format(time.RFC3339), e.Hash.String(), e.Type, e.Counterparty, amountString(e.Amount), e.Fee.String(), amountString(e.DeliveredAmount), colorResult(e.Result)})
+		}
+		printTable([]string{"DATE", "HASH", "TYPE", "COUNTERPARTY", "AMOUNT", "FEE", "DELIVERED", "RESULT"}, rows)
+	default:
+		for _, e := range entries {
+			fmt.Printf("%s %s %s %s", e.Date.Format(time.RFC3339), e.Hash, e.Type, colorResult(e.Result))
+			if e.Counterparty != "" {
+				fmt.Printf(" %s", e.Counterparty)
+			}
+			if e.Amount != nil {
+				fmt.Printf(" %s", e.Amount)
+			}
+			if e.DeliveredAmount != nil && e.Amount != nil && (!e.DeliveredAmount.SameValue(e.Amount) || e.DeliveredAmount.Currency != e.Amount.Currency) {
+				fmt.Printf(" delivered %s", e.DeliveredAmount)
+			}
+			fmt.Println()
+		}
+	}
+}
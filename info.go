@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/codegangsta/cli"
+)
+
+// info shows an account's root entry with its Flags named, so its settings
+// can be read without bit arithmetic.
+func info(c *cli.Context) {
+	account := argAccount(c)
+	if account == nil {
+		fmt.Println("Account or seed is required")
+		os.Exit(1)
+	}
+	result, err := newRemote(c).AccountInfo(*account)
+	checkErr(err)
+	root := result.AccountData
+	var flags []string
+	if root.Flags != nil {
+		flags = explainFlags(leFlags["AccountRoot"], uint32(*root.Flags))
+	}
+
+	if c.GlobalBool("json") {
+		b, err := json.Marshal(root)
+		checkErr(err)
+		var fields map[string]interface{}
+		checkErr(json.Unmarshal(b, &fields))
+		fields["FlagNames"] = flags
+		outputJSON(fields)
+		return
+	}
+	fmt.Printf("Account: %s\n", account)
+	fmt.Printf("Balance: %s XRP\n", root.Balance)
+	if root.Sequence != nil {
+		fmt.Printf("Sequence: %d\n", *root.Sequence)
+	}
+	if root.OwnerCount != nil {
+		fmt.Printf("Owner count: %d\n", *root.OwnerCount)
+	}
+	if root.RegularKey != nil {
+		fmt.Printf("Regular key: %s\n", root.RegularKey)
+	}
+	if root.Domain != nil && len(*root.Domain) > 0 {
+		fmt.Printf("Domain: %s\n", string(*root.Domain))
+	}
+	if root.TransferRate != nil && *root.TransferRate != 0 {
+		fmt.Printf("Transfer rate: %.9f\n", float64(*root.TransferRate)/1e9)
+	}
+	fmt.Printf("Flags: %s\n", strings.Join(flags, " "))
+}
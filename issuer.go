@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+// verifyIssuer checks that the domain set on account lists it in its
+// xrp-ledger.toml, returning the domain.
+func verifyIssuer(c *cli.Context, account data.Account) (string, error) {
+	info, err := newRemote(c).AccountInfo(account)
+	if err != nil {
+		return "", err
+	}
+	if info.AccountData.Domain == nil || len(*info.AccountData.Domain) == 0 {
+		return "", fmt.Errorf("%s has no domain set", account)
+	}
+	domain := string(*info.AccountData.Domain)
+	return domain, txlib.VerifyDomain(domain, account)
+}
+
+// verifyIssuerCmd checks an issuer's domain, exiting non-zero if it fails.
+func verifyIssuerCmd(c *cli.Context) {
+	if c.Args().First() == "" {
+		fmt.Println("Issuer account is required")
+		os.Exit(1)
+	}
+	account, _ := parseAddress(c.Args().First())
+	domain, err := verifyIssuer(c, *account)
+	if c.GlobalBool("json") {
+		result := map[string]interface{}{"account": account, "domain": domain, "verified": err == nil}
+		if err != nil {
+			result["error"] = err.Error()
+		}
+		outputJSON(result)
+	} else if err == nil {
+		fmt.Printf("%s is verified by %s\n", account, domain)
+	} else {
+		fmt.Println(err)
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/tx/txlib"
+	"github.com/zalando/go-keyring"
+)
+
+// Seeds are stored in the OS keychain under this service, with the key name
+// as the user.
+const keychainService = "tx"
+
+func loadKeychain(name string) string {
+	seed, err := keyring.Get(keychainService, name)
+	if err == keyring.ErrNotFound {
+		checkErr(fmt.Errorf("No key %s in the keychain", name))
+	}
+	checkErr(err)
+	return seed
+}
+
+func keychainSet(c *cli.Context) {
+	name := c.Args().First()
+	if name == "" {
+		fmt.Println("Key name is required")
+		os.Exit(1)
+	}
+	seed, err := readSecret("Seed: ")
+	checkErr(err)
+	entropy, ed25519, err := txlib.ParseSeed(seed)
+	checkErr(err)
+	ed25519 = ed25519 || c.GlobalBool("ed25519")
+	k, seq, err := txlib.NewKey(entropy, ed25519)
+	checkErr(err)
+	checkErr(keyring.Set(keychainService, name, encodeSeed(entropy, ed25519)))
+	fmt.Printf("Saved %s for %s\n", name, keyInfoFor(k, seq).Account)
+}
+
+func keychainDelete(c *cli.Context) {
+	name := c.Args().First()
+	if name == "" {
+		fmt.Println("Key name is required")
+		os.Exit(1)
+	}
+	checkErr(keyring.Delete(keychainService, name))
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/crypto"
+	"github.com/rubblelabs/tx/txlib"
+	"golang.org/x/term"
+)
+
+func encodeSeed(entropy []byte, ed25519 bool) string {
+	seed, err := txlib.EncodeSeed(entropy, ed25519)
+	checkErr(err)
+	return seed
+}
+
+// readSecret reads a line from the terminal with echo disabled. When stdin
+// is not a terminal the line is read from it a byte at a time, leaving the
+// rest of stdin for commands that read a transaction from it.
+func readSecret(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, prompt)
+		b, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		return strings.TrimSpace(string(b)), err
+	}
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(b)
+		if n == 1 && b[0] != '\n' {
+			line = append(line, b[0])
+			continue
+		}
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		if n == 1 || err == io.EOF {
+			return strings.TrimSpace(string(line)), nil
+		}
+	}
+}
+
+type keyInfo struct {
+	PublicKey    string `json:"public_key"`
+	PublicKeyHex string `json:"public_key_hex"`
+	Account      string `json:"account"`
+	XAddress     string `json:"x_address,omitempty"`
+}
+
+func describeKey(entropy []byte, ed25519 bool) keyInfo {
+	k, seq, err := txlib.NewKey(entropy, ed25519)
+	checkErr(err)
+	return keyInfoFor(k, seq)
+}
+
+func keyInfoFor(k crypto.Key, seq *uint32) keyInfo {
+	pub, err := crypto.NewAccountPublicKey(k.Public(seq))
+	checkErr(err)
+	account, err := crypto.NewAccountId(k.Id(seq))
+	checkErr(err)
+	return keyInfo{
+		PublicKey:    pub.String(),
+		PublicKeyHex: fmt.Sprintf("%X", k.Public(seq)),
+		Account:      account.String(),
+	}
+}
+
+func printKeys(c *cli.Context, entropy []byte) {
+	secp256k1, ed25519 := describeKey(entropy, false), describeKey(entropy, true)
+	words, err := txlib.EncodeRFC1751(entropy)
+	checkErr(err)
+	if c.GlobalBool("json") {
+		outputJSON(map[string]interface{}{
+			"hex":          fmt.Sprintf("%X", entropy),
+			"seed":         encodeSeed(entropy, false),
+			"ed25519_seed": encodeSeed(entropy, true),
+			"rfc1751":      words,
+			"secp256k1":    secp256k1,
+			"ed25519":      ed25519,
+		})
+		return
+	}
+	fmt.Printf("Hex: %X\n", entropy)
+	fmt.Printf("Seed: %s\n", encodeSeed(entropy, false))
+	fmt.Printf("Ed25519 seed: %s\n", encodeSeed(entropy, true))
+	fmt.Printf("RFC 1751: %s\n", words)
+	for _, k := range []struct {
+		name string
+		info keyInfo
+	}{{"secp256k1", secp256k1}, {"ed25519", ed25519}} {
+		fmt.Printf("%s public key: %s (%s)\n", k.name, k.info.PublicKey, k.info.PublicKeyHex)
+		fmt.Printf("%s account: %s\n", k.name, k.info.Account)
+	}
+}
+
+// keyconvert shows a seed given as a family seed, hex entropy or RFC 1751
+// words in each of those formats, with the keys derived from it.
+func keyconvert(c *cli.Context) {
+	// The words may be given as one argument or several.
+	s := strings.Join(c.Args(), " ")
+	if strings.TrimSpace(s) == "" {
+		fmt.Println("Seed, hex entropy or RFC 1751 words are required")
+		os.Exit(1)
+	}
+	var entropy []byte
+	var err error
+	switch {
+	case len(strings.Fields(s)) > 1:
+		entropy, err = txlib.DecodeRFC1751(s)
+		checkErr(err)
+	case strings.HasPrefix(s, "s"):
+		entropy, _, err = txlib.ParseSeed(s)
+		checkErr(err)
+	default:
+		entropy, err = hex.DecodeString(s)
+		checkErr(err)
+		if len(entropy) != 16 {
+			checkErr(fmt.Errorf("Hex entropy must be 16 bytes, got %d", len(entropy)))
+		}
+	}
+	printKeys(c, entropy)
+}
+
+// keygen prints a new random seed. The seed is encoded as sEd... for
+// ed25519 so that it is not mistaken for a secp256k1 seed later.
+func keygen(c *cli.Context) {
+	entropy := make([]byte, 16)
+	_, err := rand.Read(entropy)
+	checkErr(err)
+	ed25519 := c.GlobalBool("ed25519")
+	seed, info := encodeSeed(entropy, ed25519), describeKey(entropy, ed25519)
+
+	if c.GlobalBool("json") {
+		outputJSON(map[string]string{
+			"seed":           seed,
+			"public_key":     info.PublicKey,
+			"public_key_hex": info.PublicKeyHex,
+			"account":        info.Account,
+		})
+		return
+	}
+	fmt.Printf("Seed: %s\n", seed)
+	fmt.Printf("Public key: %s (%s)\n", info.PublicKey, info.PublicKeyHex)
+	fmt.Printf("Account: %s\n", info.Account)
+}
+
+// whoami shows the account the seed signs for, and optionally the account
+// at another index of a secp256k1 key family.
+func whoami(c *cli.Context) {
+	if key == nil {
+		fmt.Println("Seed is required")
+		os.Exit(1)
+	}
+	keys := map[string]keyInfo{"master": keyInfoFor(key, keySequence)}
+	if c.IsSet("index") {
+		if keySequence == nil {
+			checkErr(fmt.Errorf("Key sequences only apply to secp256k1 seeds"))
+		}
+		seq := uint32(c.Int("index"))
+		keys[fmt.Sprintf("index %d", seq)] = keyInfoFor(key, &seq)
+	}
+	for name, info := range keys {
+		info.XAddress = txlib.EncodeXAddress(*parseAccount(info.Account), nil, false)
+		keys[name] = info
+	}
+	printQR(c, keyAccount(c).String())
+
+	if c.GlobalBool("json") {
+		outputJSON(keys)
+		return
+	}
+	for _, name := range []string{"master", fmt.Sprintf("index %d", c.Int("index"))} {
+		info, ok := keys[name]
+		if !ok {
+			continue
+		}
+		fmt.Printf("%s:\n", strings.Title(name))
+		fmt.Printf("  Account: %s\n", info.Account)
+		fmt.Printf("  X-address: %s\n", info.XAddress)
+		fmt.Printf("  Public key: %s (%s)\n", info.PublicKey, info.PublicKeyHex)
+	}
+	if c.GlobalString("account") != "" {
+		fmt.Printf("Signing for: %s\n", keyAccount(c))
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/tx/txlib"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+// keyFile is a seed encrypted with XChaCha20-Poly1305 under a key derived
+// from a passphrase with scrypt. The account is stored in the clear so keys
+// can be listed without the passphrase, and is authenticated with the seed.
+type keyFile struct {
+	Version    int    `json:"version"`
+	Account    string `json:"account"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       string `json:"salt"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+func keystoreDir(c *cli.Context) string {
+	if dir := c.GlobalString("keystore"); dir != "" {
+		return dir
+	}
+	dir, err := os.UserConfigDir()
+	checkErr(err)
+	return filepath.Join(dir, "tx", "keystore")
+}
+
+func keyFilePath(c *cli.Context, name string) string {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		checkErr(fmt.Errorf("Bad key name: %q", name))
+	}
+	return filepath.Join(keystoreDir(c), name+".json")
+}
+
+func keystorePassphrase(c *cli.Context, confirm bool) string {
+	if p := c.GlobalString("key-passphrase"); p != "" {
+		return p
+	}
+	passphrase, err := readSecret("Passphrase: ")
+	checkErr(err)
+	if confirm {
+		again, err := readSecret("Confirm passphrase: ")
+		checkErr(err)
+		if again != passphrase {
+			checkErr(fmt.Errorf("Passphrases do not match"))
+		}
+	}
+	if passphrase == "" {
+		checkErr(fmt.Errorf("A passphrase is required"))
+	}
+	return passphrase
+}
+
+func encryptSeed(seed, account, passphrase string) (*keyFile, error) {
+	f := &keyFile{Version: 1, Account: account, KDF: "scrypt", N: 1 << 15, R: 8, P: 1}
+	salt := make([]byte, 16)
+	nonce := make([]byte, chacha20poly1305.NonceSizeX)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	k, err := scrypt.Key([]byte(passphrase), salt, f.N, f.R, f.P, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.NewX(k)
+	if err != nil {
+		return nil, err
+	}
+	f.Salt = hex.EncodeToString(salt)
+	f.Nonce = hex.EncodeToString(nonce)
+	f.Ciphertext = hex.EncodeToString(aead.Seal(nil, nonce, []byte(seed), []byte(account)))
+	return f, nil
+}
+
+func decryptSeed(f *keyFile, passphrase string) (string, error) {
+	if f.Version != 1 || f.KDF != "scrypt" {
+		return "", fmt.Errorf("Unsupported key file version: %d", f.Version)
+	}
+	salt, err := hex.DecodeString(f.Salt)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := hex.DecodeString(f.Nonce)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := hex.DecodeString(f.Ciphertext)
+	if err != nil {
+		return "", err
+	}
+	k, err := scrypt.Key([]byte(passphrase), salt, f.N, f.R, f.P, chacha20poly1305.KeySize)
+	if err != nil {
+		return "", err
+	}
+	aead, err := chacha20poly1305.NewX(k)
+	if err != nil {
+		return "", err
+	}
+	seed, err := aead.Open(nil, nonce, ciphertext, []byte(f.Account))
+	if err != nil {
+		return "", fmt.Errorf("Wrong passphrase or corrupt key file")
+	}
+	return string(seed), nil
+}
+
+func readKeyFile(c *cli.Context, name string) *keyFile {
+	b, err := ioutil.ReadFile(keyFilePath(c, name))
+	checkErr(err)
+	var f keyFile
+	checkErr(json.Unmarshal(b, &f))
+	return &f
+}
+
+// loadKeyFile returns the seed stored under name, prompting for the
+// passphrase unless --key-passphrase is set.
+func loadKeyFile(c *cli.Context, name string) string {
+	seed, err := decryptSeed(readKeyFile(c, name), keystorePassphrase(c, false))
+	checkErr(err)
+	return seed
+}
+
+// keystoreCreate encrypts a seed read from the terminal, or a new random
+// seed with --generate, and saves it under the given name.
+func keystoreCreate(c *cli.Context) {
+	name := c.Args().First()
+	path := keyFilePath(c, name)
+	if _, err := os.Stat(path); err == nil {
+		checkErr(fmt.Errorf("Key %s already exists", name))
+	}
+
+	var seed string
+	if c.Bool("generate") {
+		entropy := make([]byte, 16)
+		_, err := rand.Read(entropy)
+		checkErr(err)
+		seed = encodeSeed(entropy, c.GlobalBool("ed25519"))
+	} else {
+		var err error
+		seed, err = readSecret("Seed: ")
+		checkErr(err)
+	}
+	entropy, ed25519, err := txlib.ParseSeed(seed)
+	checkErr(err)
+	// Store ed25519 seeds as sEd... so the key file needs no --ed25519.
+	ed25519 = ed25519 || c.GlobalBool("ed25519")
+	seed = encodeSeed(entropy, ed25519)
+	k, seq, err := txlib.NewKey(entropy, ed25519)
+	checkErr(err)
+	account := keyInfoFor(k, seq).Account
+
+	f, err := encryptSeed(seed, account, keystorePassphrase(c, true))
+	checkErr(err)
+	b, err := json.MarshalIndent(f, "", "  ")
+	checkErr(err)
+	checkErr(os.MkdirAll(filepath.Dir(path), 0700))
+	checkErr(ioutil.WriteFile(path, b, 0600))
+	fmt.Printf("Saved %s for %s\n", name, account)
+}
+
+func keystoreList(c *cli.Context) {
+	paths, err := filepath.Glob(filepath.Join(keystoreDir(c), "*.json"))
+	checkErr(err)
+	sort.Strings(paths)
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		fmt.Printf("%s %s\n", name, readKeyFile(c, name).Account)
+	}
+}
+
+// readSeedFile returns the seed in a file. Files ending .gpg, .pgp or .asc
+// are decrypted by running gpg, which prompts for its passphrase itself.
+func readSeedFile(path string) string {
+	var b []byte
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gpg", ".pgp", ".asc":
+		cmd := exec.Command("gpg", "--quiet", "--decrypt", path)
+		cmd.Stderr = os.Stderr
+		b, err = cmd.Output()
+	default:
+		b, err = ioutil.ReadFile(path)
+	}
+	checkErr(err)
+	return strings.TrimSpace(string(b))
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+// awsKMSKey signs with an ECC_SECG_P256K1 key held in AWS KMS. Credentials
+// and region come from the usual AWS environment and config files.
+type awsKMSKey struct {
+	txlib.RemotePublicKey
+	client *kms.Client
+	id     string
+}
+
+func newAWSKMSKey(id string) (*awsKMSKey, error) {
+	ctx := context.Background()
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	k := &awsKMSKey{client: kms.NewFromConfig(cfg), id: id}
+	out, err := k.client.GetPublicKey(ctx, &kms.GetPublicKeyInput{KeyId: aws.String(id)})
+	if err != nil {
+		return nil, err
+	}
+	if out.KeySpec != types.KeySpecEccSecgP256k1 {
+		return nil, fmt.Errorf("KMS key %s is %s, only %s keys can sign", id, out.KeySpec, types.KeySpecEccSecgP256k1)
+	}
+	// The key is a DER SubjectPublicKeyInfo, which crypto/x509 cannot parse
+	// for secp256k1.
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(out.PublicKey, &spki); err != nil {
+		return nil, err
+	}
+	pub, err := btcec.ParsePubKey(spki.PublicKey.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	k.RemotePublicKey = pub.SerializeCompressed()
+	return k, nil
+}
+
+func (k *awsKMSKey) Sign(hash, msg []byte) ([]byte, error) {
+	out, err := k.client.Sign(context.Background(), &kms.SignInput{
+		KeyId:            aws.String(k.id),
+		Message:          hash,
+		MessageType:      types.MessageTypeDigest,
+		SigningAlgorithm: types.SigningAlgorithmSpecEcdsaSha256,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// KMS may return a high S value, which the ledger rejects as not fully
+	// canonical. Serialize normalizes it.
+	sig, err := ecdsa.ParseDERSignature(out.Signature)
+	if err != nil {
+		return nil, err
+	}
+	return sig.Serialize(), nil
+}
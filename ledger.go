@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/karalabe/hid"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+const (
+	ledgerVendorID = 0x2c97
+
+	// XRP app instructions. The curve is passed in P2.
+	ledgerGetPublicKey = 0x02
+	ledgerSign         = 0x04
+	ledgerSecp256k1    = 0x40
+
+	// The XRP app's largest APDU payload
+	ledgerChunkSize = 150
+)
+
+// ledgerKey signs with the secp256k1 key at a BIP44 path on a Ledger
+// device's XRP app. The transaction is shown on the device for approval.
+type ledgerKey struct {
+	txlib.RemotePublicKey
+	device *hid.Device
+	path   []byte
+}
+
+// newLedgerKey opens the first Ledger found and reads the public key at
+// the path.
+func newLedgerKey(path []uint32) (*ledgerKey, error) {
+	var device *hid.Device
+	for _, info := range hid.Enumerate(ledgerVendorID, 0) {
+		// The APDU interface is 0, or usage page 0xffa0 where the
+		// interface number is not reported.
+		if info.Interface == 0 || info.UsagePage == 0xffa0 {
+			var err error
+			if device, err = info.Open(); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	if device == nil {
+		return nil, fmt.Errorf("No Ledger device found")
+	}
+
+	l := &ledgerKey{device: device, path: []byte{byte(len(path))}}
+	for _, index := range path {
+		l.path = binary.BigEndian.AppendUint32(l.path, index)
+	}
+	reply, err := l.exchange(ledgerGetPublicKey, 0, ledgerSecp256k1, l.path)
+	if err != nil {
+		return nil, err
+	}
+	if len(reply) < 1 || len(reply) < 1+int(reply[0]) {
+		return nil, fmt.Errorf("Short public key reply from Ledger")
+	}
+	pub, err := btcec.ParsePubKey(reply[1 : 1+reply[0]])
+	if err != nil {
+		return nil, err
+	}
+	l.RemotePublicKey = pub.SerializeCompressed()
+	return l, nil
+}
+
+// exchange sends an APDU and returns the reply without its status word.
+// The APDU is framed in 64 byte HID reports on channel 0x0101.
+func (l *ledgerKey) exchange(ins, p1, p2 byte, data []byte) ([]byte, error) {
+	apdu := binary.BigEndian.AppendUint16(nil, uint16(5+len(data)))
+	apdu = append(apdu, 0xe0, ins, p1, p2, byte(len(data)))
+	apdu = append(apdu, data...)
+
+	header := []byte{0x01, 0x01, 0x05, 0x00, 0x00}
+	for i := 0; len(apdu) > 0; i++ {
+		chunk := append(make([]byte, 0, 64), header...)
+		binary.BigEndian.PutUint16(chunk[3:], uint16(i))
+		n := 64 - len(header)
+		if n > len(apdu) {
+			n = len(apdu)
+		}
+		chunk = append(chunk, apdu[:n]...)
+		apdu = apdu[n:]
+		// Reports are always written in full, zero padded.
+		if _, err := l.device.Write(chunk[:64]); err != nil {
+			return nil, err
+		}
+	}
+
+	var reply []byte
+	chunk := make([]byte, 64)
+	for i := 0; ; i++ {
+		if _, err := io.ReadFull(l.device, chunk); err != nil {
+			return nil, err
+		}
+		if chunk[0] != 0x01 || chunk[1] != 0x01 || chunk[2] != 0x05 || int(binary.BigEndian.Uint16(chunk[3:])) != i {
+			return nil, fmt.Errorf("Bad reply from Ledger")
+		}
+		payload := chunk[5:]
+		if i == 0 {
+			reply = make([]byte, 0, binary.BigEndian.Uint16(chunk[5:]))
+			payload = chunk[7:]
+		}
+		left := cap(reply) - len(reply)
+		if left > len(payload) {
+			reply = append(reply, payload...)
+			continue
+		}
+		reply = append(reply, payload[:left]...)
+		break
+	}
+	if len(reply) < 2 {
+		return nil, fmt.Errorf("Short reply from Ledger")
+	}
+	if sw := binary.BigEndian.Uint16(reply[len(reply)-2:]); sw != 0x9000 {
+		return nil, fmt.Errorf("Ledger returned status %04X, is the XRP app open and the transaction approved?", sw)
+	}
+	return reply[:len(reply)-2], nil
+}
+
+// Sign sends the transaction in chunks, the first prefixed with the path.
+// The app adds the signing prefix itself, so it is stripped from msg.
+func (l *ledgerKey) Sign(hash, msg []byte) ([]byte, error) {
+	tx := msg[4:]
+	data := append([]byte{}, l.path...)
+	var reply []byte
+	for first := true; first || len(tx) > 0; first = false {
+		n := ledgerChunkSize - len(data)
+		if n > len(tx) {
+			n = len(tx)
+		}
+		data = append(data, tx[:n]...)
+		tx = tx[n:]
+		p1 := byte(0x80)
+		if first {
+			p1 = 0x00
+		}
+		var err error
+		if reply, err = l.exchange(ledgerSign, p1, ledgerSecp256k1, data); err != nil {
+			return nil, err
+		}
+		data = nil
+	}
+	return reply, nil
+}
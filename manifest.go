@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/crypto"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+type manifestResult struct {
+	MasterKey       string  `json:"master_key"`
+	SigningKey      string  `json:"signing_key,omitempty"`
+	Sequence        uint32  `json:"seq"`
+	Version         *uint16 `json:"version,omitempty"`
+	Domain          string  `json:"domain,omitempty"`
+	Revoked         bool    `json:"revoked"`
+	MasterSignature string  `json:"master_signature"`
+	Signature       string  `json:"signature,omitempty"`
+}
+
+// nodePublicKey shows a validator key in base58, as in validator lists.
+func nodePublicKey(b []byte) string {
+	pub, err := crypto.NewNodePublicKey(b)
+	checkErr(err)
+	return pub.String()
+}
+
+// manifestDecode shows the keys, sequence and domain of a base64 validator
+// manifest and verifies its signatures. It exits with 1 if either is bad.
+func manifestDecode(c *cli.Context) {
+	s := c.Args().First()
+	if s == "" {
+		b, err := ioutil.ReadAll(os.Stdin)
+		checkErr(err)
+		s = string(b)
+	}
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	checkErr(err)
+	m, err := txlib.ParseManifest(b)
+	checkErr(err)
+
+	result := manifestResult{
+		MasterKey:       nodePublicKey(m.MasterKey),
+		Sequence:        m.Sequence,
+		Version:         m.Version,
+		Domain:          string(m.Domain),
+		Revoked:         m.Revoked(),
+		MasterSignature: validity(m.VerifyMaster()),
+	}
+	valid := m.VerifyMaster()
+	if !m.Revoked() {
+		result.SigningKey = nodePublicKey(m.SigningKey)
+		result.Signature = validity(m.VerifySigning())
+		valid = valid && m.VerifySigning()
+	}
+
+	if c.GlobalBool("json") {
+		outputJSON(result)
+	} else {
+		fmt.Printf("Master key: %s\n", result.MasterKey)
+		if m.Revoked() {
+			fmt.Println("Revoked: the master key can no longer validate")
+		} else {
+			fmt.Printf("Signing key: %s\n", result.SigningKey)
+		}
+		fmt.Printf("Sequence: %d\n", result.Sequence)
+		if result.Domain != "" {
+			fmt.Printf("Domain: %s\n", result.Domain)
+		}
+		fmt.Printf("Master signature: %s\n", result.MasterSignature)
+		if !m.Revoked() {
+			fmt.Printf("Signature: %s\n", result.Signature)
+		}
+	}
+	if !valid {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/codegangsta/cli"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	signedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "tx_signed_total",
+		Help: "Transactions signed.",
+	})
+	submittedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "tx_submitted_total",
+		Help: "Transactions submitted to the server.",
+	})
+	validatedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "tx_validated_total",
+		Help: "Transactions seen in a validated ledger by watch or wait, or after serve submitted them.",
+	})
+	failedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "tx_failed_total",
+		Help: "Transactions rejected on submission, failed in a validated ledger or expired.",
+	})
+	submitSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "tx_submit_duration_seconds",
+		Help:    "Time for the server to answer a submission.",
+		Buckets: prometheus.DefBuckets,
+	})
+)
+
+func init() {
+	prometheus.MustRegister(signedTotal, submittedTotal, validatedTotal, failedTotal, submitSeconds)
+}
+
+// serveMetrics serves Prometheus metrics at /metrics on the --metrics
+// address, for commands that keep running.
+func serveMetrics(c *cli.Context) {
+	addr := c.GlobalString("metrics")
+	if addr == "" {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	go func() {
+		checkErr(http.ListenAndServe(addr, mux))
+	}()
+}
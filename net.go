@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/gorilla/websocket"
+)
+
+// setProxy sends every websocket and HTTP connection through --proxy, an
+// http:// or socks5:// url. Without it the HTTPS_PROXY, HTTP_PROXY and
+// NO_PROXY environment variables are honored, as both transports default
+// to http.ProxyFromEnvironment.
+func setProxy(c *cli.Context) error {
+	if c.GlobalString("proxy") == "" {
+		return nil
+	}
+	u, err := url.Parse(c.GlobalString("proxy"))
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "socks5" {
+		return fmt.Errorf("Unsupported proxy %s, use an http:// or socks5:// url", u.Redacted())
+	}
+	// The websockets package dials with the default dialer, so it is
+	// changed rather than replaced.
+	websocket.DefaultDialer.Proxy = http.ProxyURL(u)
+	http.DefaultTransport.(*http.Transport).Proxy = http.ProxyURL(u)
+	return nil
+}
+
+// setTLS configures the websocket connection for a rippled with a private
+// CA, --ca-cert, or one requiring a client certificate, --client-cert and
+// --client-key. --insecure skips checking the server's certificate.
+func setTLS(c *cli.Context) error {
+	if c.GlobalString("ca-cert") == "" && c.GlobalString("client-cert") == "" && !c.GlobalBool("insecure") {
+		return nil
+	}
+	config := &tls.Config{InsecureSkipVerify: c.GlobalBool("insecure")}
+	if path := c.GlobalString("ca-cert"); path != "" {
+		pem, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("No PEM certificates in %s", path)
+		}
+	}
+	if c.GlobalString("client-cert") != "" || c.GlobalString("client-key") != "" {
+		cert, err := tls.LoadX509KeyPair(c.GlobalString("client-cert"), c.GlobalString("client-key"))
+		if err != nil {
+			return fmt.Errorf("Client certificate: %s", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	websocket.DefaultDialer.TLSClientConfig = config
+	return nil
+}
+
+// deadlineConn fails a read that has not returned within timeout of the
+// last write, so a request to an unresponsive server errors instead of
+// hanging. An idle connection, like a subscription between messages, never
+// times out.
+type deadlineConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (d *deadlineConn) Write(b []byte) (int, error) {
+	if err := d.Conn.SetReadDeadline(time.Now().Add(d.timeout)); err != nil {
+		return 0, err
+	}
+	return d.Conn.Write(b)
+}
+
+func (d *deadlineConn) Read(b []byte) (int, error) {
+	n, err := d.Conn.Read(b)
+	if n > 0 {
+		d.Conn.SetReadDeadline(time.Time{})
+	}
+	return n, err
+}
+
+// setTimeouts limits how long connecting to a server, --connect-timeout,
+// and waiting for it to answer, --request-timeout, may take. A timeout of
+// 0 waits forever.
+func setTimeouts(c *cli.Context) {
+	connect, request := c.GlobalDuration("connect-timeout"), c.GlobalDuration("request-timeout")
+	dialer := &net.Dialer{Timeout: connect, KeepAlive: 30 * time.Second}
+
+	websocket.DefaultDialer.HandshakeTimeout = connect
+	websocket.DefaultDialer.NetDialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dialer.DialContext(ctx, network, addr)
+		if err != nil || request == 0 {
+			return conn, err
+		}
+		return &deadlineConn{Conn: conn, timeout: request}, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport)
+	transport.DialContext = dialer.DialContext
+	transport.TLSHandshakeTimeout = connect
+	transport.ResponseHeaderTimeout = request
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/ripple/websockets"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+// NFToken flags, as stored in the NFTokenID and the NFTokenMint Flags field
+const (
+	nftBurnable  = 0x0001
+	nftOnlyXRP   = 0x0002
+	nftTrustLine = 0x0004
+)
+
+// The NFTokenCreateOffer flag for a sell offer
+const nftSellOffer = 0x00000001
+
+type accountNFT struct {
+	Flags        uint32              `json:"Flags"`
+	Issuer       data.Account        `json:"Issuer"`
+	NFTokenID    data.Hash256        `json:"NFTokenID"`
+	NFTokenTaxon uint32              `json:"NFTokenTaxon"`
+	URI          data.VariableLength `json:"URI,omitempty"`
+	Serial       uint32              `json:"nft_serial"`
+	TransferFee  uint16              `json:"TransferFee"`
+}
+
+type nftOffer struct {
+	Amount      data.Amount   `json:"amount"`
+	Flags       uint32        `json:"flags"`
+	Index       data.Hash256  `json:"nft_offer_index"`
+	Owner       data.Account  `json:"owner"`
+	Destination *data.Account `json:"destination,omitempty"`
+	Expiration  *uint32       `json:"expiration,omitempty"`
+}
+
+func nftList(c *cli.Context) {
+	account := argAccount(c)
+	if account == nil {
+		fmt.Println("Account or seed is required")
+		os.Exit(1)
+	}
+
+	var nfts []accountNFT
+	var marker interface{}
+	for {
+		params := map[string]interface{}{
+			"account":      account.String(),
+			"ledger_index": "validated",
+			"limit":        c.Int("limit"),
+		}
+		if marker != nil {
+			params["marker"] = marker
+		}
+		var result struct {
+			NFTs   []accountNFT `json:"account_nfts"`
+			Marker interface{}  `json:"marker"`
+		}
+		checkErr(request(c, "account_nfts", params, &result))
+		nfts = append(nfts, result.NFTs...)
+		if marker = result.Marker; marker == nil {
+			break
+		}
+	}
+
+	if c.GlobalBool("json") {
+		outputJSON(nfts)
+		return
+	}
+	if outputTable {
+		var rows [][]string
+		for _, nft := range nfts {
+			rows = append(rows, []string{nft.NFTokenID.String(), nft.Issuer.String(), fmt.Sprint(nft.NFTokenTaxon), fmt.Sprint(nft.Serial), fmt.Sprint(nft.TransferFee), strings.Join(explainFlags(txFlags["NFTokenMint"], nft.Flags), ","), string(nft.URI)})
+		}
+		printTable([]string{"ID", "ISSUER", "TAXON", "SERIAL", "FEE", "FLAGS", "URI"}, rows)
+		return
+	}
+	for _, nft := range nfts {
+		fmt.Printf("%s Issuer: %s Taxon: %d Serial: %d Fee: %d Flags: %s\n", nft.NFTokenID, nft.Issuer, nft.NFTokenTaxon, nft.Serial, nft.TransferFee, strings.Join(explainFlags(txFlags["NFTokenMint"], nft.Flags), ","))
+		if len(nft.URI) > 0 {
+			fmt.Printf("  URI: %s\n", string(nft.URI))
+		}
+	}
+}
+
+// fetchNFTOffers returns all buy or sell offers for a token. The server
+// reports a token without offers as objectNotFound.
+func fetchNFTOffers(c *cli.Context, command string, id *data.Hash256) []nftOffer {
+	var offers []nftOffer
+	var marker interface{}
+	for {
+		params := map[string]interface{}{
+			"nft_id":       id.String(),
+			"ledger_index": "validated",
+		}
+		if marker != nil {
+			params["marker"] = marker
+		}
+		var result struct {
+			Offers []nftOffer  `json:"offers"`
+			Marker interface{} `json:"marker"`
+		}
+		err := request(c, command, params, &result)
+		if e, ok := err.(*websockets.CommandError); ok && e.Name == "objectNotFound" {
+			return offers
+		}
+		checkErr(err)
+		offers = append(offers, result.Offers...)
+		if marker = result.Marker; marker == nil {
+			return offers
+		}
+	}
+}
+
+func printNFTOffers(title string, offers []nftOffer) {
+	fmt.Println(title)
+	if outputTable {
+		var rows [][]string
+		for _, offer := range offers {
+			destination, expires := "", ""
+			if offer.Destination != nil {
+				destination = offer.Destination.String()
+			}
+			if offer.Expiration != nil {
+				expires = data.NewRippleTime(*offer.Expiration).String()
+			}
+			rows = append(rows, []string{offer.Index.String(), offer.Owner.String(), offer.Amount.String(), destination, expires})
+		}
+		printTable([]string{"OFFER", "OWNER", "AMOUNT", "DESTINATION", "EXPIRES"}, rows)
+		return
+	}
+	for _, offer := range offers {
+		fmt.Printf("  %s Owner: %s Amount: %s", offer.Index, offer.Owner, offer.Amount)
+		if offer.Destination != nil {
+			fmt.Printf(" Destination: %s", offer.Destination)
+		}
+		if offer.Expiration != nil {
+			fmt.Printf(" Expires: %s", data.NewRippleTime(*offer.Expiration))
+		}
+		fmt.Println()
+	}
+}
+
+func nftOffers(c *cli.Context) {
+	if c.Args().First() == "" {
+		fmt.Println("Token id is required")
+		os.Exit(1)
+	}
+	id, err := data.NewHash256(c.Args().First())
+	checkErr(err)
+
+	buy := fetchNFTOffers(c, "nft_buy_offers", id)
+	sell := fetchNFTOffers(c, "nft_sell_offers", id)
+
+	if c.GlobalBool("json") {
+		outputJSON(map[string][]nftOffer{"buy": buy, "sell": sell})
+		return
+	}
+	printNFTOffers("Buy offers:", buy)
+	printNFTOffers("Sell offers:", sell)
+}
+
+// nftURI returns the --uri, or the ipfs:// URI of the --uri-file, or nil if
+// neither is given.
+func nftURI(c *cli.Context) *data.VariableLength {
+	s := c.String("uri")
+	if c.String("uri-file") != "" {
+		if s != "" {
+			fmt.Println("Only one of --uri and --uri-file can be used")
+			os.Exit(1)
+		}
+		b, err := ioutil.ReadFile(c.String("uri-file"))
+		checkErr(err)
+		cid, err := txlib.FileCID(b)
+		checkErr(err)
+		s = "ipfs://" + cid
+		fmt.Fprintf(os.Stderr, "URI: %s, pin the file so it can be fetched\n", s)
+	}
+	if s == "" {
+		return nil
+	}
+	b, err := txlib.NFTokenURI(s)
+	checkErr(err)
+	uri := data.VariableLength(b)
+	return &uri
+}
+
+func nftMint(c *cli.Context) {
+	if !haveSigner(c) {
+		fmt.Println("Seed is required")
+		os.Exit(1)
+	}
+	taxon, err := strconv.ParseUint(c.String("taxon"), 10, 32)
+	if err != nil {
+		checkErr(fmt.Errorf("Taxon %s is not a number from 0 to 4294967295", c.String("taxon")))
+	}
+	tx := txlib.NewNFTokenMint(uint32(taxon))
+	tx.URI = nftURI(c)
+	nftMintFlags(c, tx)
+	sign(c, tx)
+	outputTx(c, tx)
+}
+
+// nftMintFlags applies the mint flags, and --transfer-fee unless the mint
+// already has a fee.
+func nftMintFlags(c *cli.Context, tx *data.NFTokenMint) {
+	if c.Bool("burnable") {
+		*tx.Flags = *tx.Flags | nftBurnable
+	}
+	if c.Bool("only-xrp") {
+		*tx.Flags = *tx.Flags | nftOnlyXRP
+	}
+	if c.Bool("transferable") {
+		*tx.Flags = *tx.Flags | txlib.NFTokenTransferable
+	}
+	if c.String("transfer-fee") != "" && tx.TransferFee == nil {
+		fee, err := txlib.ParseTransferFee(c.String("transfer-fee"))
+		checkErr(err)
+		// rippled refuses a fee on a token that cannot be transferred.
+		if fee > 0 {
+			tx.TransferFee = &fee
+			*tx.Flags = *tx.Flags | txlib.NFTokenTransferable
+		}
+	}
+}
+
+// nftMintBatch signs a mint for every token of a manifest, numbering them
+// from --sequence or else the account's next sequence, and writes the
+// hashes and blobs as CSV. Rows may name the key to mint with, as for
+// payout. With --submit each is submitted in turn, --interval
+// apart, stopping at the first that fails.
+func nftMintBatch(c *cli.Context) {
+	if c.Args().First() == "" {
+		fmt.Println("Manifest file is required")
+		os.Exit(1)
+	}
+	f, err := os.Open(c.Args().First())
+	checkErr(err)
+	defer f.Close()
+	mints, err := txlib.ReadMintManifest(f)
+	checkErr(err)
+	named := false
+	for _, m := range mints {
+		named = named || m.Key != ""
+	}
+
+	var out io.Writer = os.Stdout
+	var buf bytes.Buffer
+	if c.String("out") != "" {
+		out = &buf
+	}
+	w := csv.NewWriter(out)
+	header := []string{"sequence", "uri", "taxon", "hash", "blob"}
+	if named {
+		header = append(header, "account")
+	}
+	if c.GlobalBool("submit") {
+		header = append(header, "result")
+	}
+	checkErr(w.Write(header))
+	keys := newRowKeys(c)
+	var failed error
+	for i, m := range mints {
+		mint := m.Mint
+		nftMintFlags(c, mint)
+		keys.sign(m.Key, mint)
+		_, raw, err := data.Raw(mint)
+		checkErr(err)
+		uri, hash := "", ""
+		if mint.URI != nil {
+			uri = string(*mint.URI)
+		}
+		if !mint.GetHash().IsZero() {
+			hash = mint.GetHash().String()
+		}
+		row := []string{
+			strconv.FormatUint(uint64(mint.Sequence), 10),
+			uri,
+			strconv.FormatUint(uint64(mint.NFTokenTaxon), 10),
+			hash,
+			fmt.Sprintf("%X", raw),
+		}
+		if named {
+			row = append(row, mint.Account.String())
+		}
+		if c.GlobalBool("submit") {
+			if i > 0 {
+				time.Sleep(c.Duration("interval"))
+			}
+			result := submitResult(c, mint)
+			row = append(row, result.EngineResult.String())
+			if !result.EngineResult.Success() && !result.EngineResult.Queued() {
+				// The later mints would wait forever for this sequence.
+				failed = fmt.Errorf("Mint %d of %d failed with %s: %s", i+1, len(mints), result.EngineResult, result.EngineResultMessage)
+			}
+		}
+		checkErr(w.Write(row))
+		if failed != nil {
+			break
+		}
+	}
+	w.Flush()
+	checkErr(w.Error())
+	if c.String("out") != "" {
+		checkErr(writeFileAtomic(c.String("out"), buf.Bytes()))
+	}
+	checkErr(failed)
+}
+
+// nftCreateOffer offers to sell a token the account holds, or to buy one
+// from its --owner. --destination makes it a private sale, only that
+// account can accept it.
+func nftCreateOffer(c *cli.Context) {
+	if c.Args().First() == "" || c.String("amount") == "" || !haveSigner(c) {
+		fmt.Println("Token id, amount and seed are required")
+		os.Exit(1)
+	}
+	id, err := data.NewHash256(c.Args().First())
+	checkErr(err)
+	tx := txlib.NewNFTokenCreateOffer(*id, *parseAmount(c.String("amount")))
+
+	switch {
+	case c.Bool("sell") && c.String("owner") != "":
+		fmt.Println("A sell offer is for a token the account holds, so it has no --owner")
+		os.Exit(1)
+	case c.Bool("sell"):
+		*tx.Flags = *tx.Flags | nftSellOffer
+	case c.String("owner") == "":
+		fmt.Println("A buy offer needs the --owner of the token")
+		os.Exit(1)
+	default:
+		tx.Owner = parseAccount(c.String("owner"))
+	}
+	if c.String("destination") != "" {
+		tx.Destination = parseAccount(c.String("destination"))
+	}
+	tx.Expiration = parseExpires(c)
+	sign(c, tx)
+	outputTx(c, tx)
+}
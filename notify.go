@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+)
+
+// notification is the JSON posted to --notify-url when a transaction
+// reaches a final state.
+type notification struct {
+	Hash            data.Hash256     `json:"hash"`
+	TransactionType string           `json:"transaction_type"`
+	Result          string           `json:"result"`
+	LedgerIndex     uint32           `json:"ledger_index"`
+	DeliveredAmount *data.Amount     `json:"delivered_amount,omitempty"`
+	Transaction     data.Transaction `json:"transaction"`
+}
+
+func newNotification(txm *data.TransactionWithMetaData, ledger uint32) *notification {
+	return &notification{
+		Hash:            *txm.GetHash(),
+		TransactionType: txm.GetType(),
+		Result:          txm.MetaData.TransactionResult.String(),
+		LedgerIndex:     ledger,
+		DeliveredAmount: txm.MetaData.DeliveredAmount,
+		Transaction:     txm.Transaction,
+	}
+}
+
+var notifyClient = &http.Client{Timeout: 10 * time.Second}
+
+// notify posts n to --notify-url if it is set. Failures are only warnings,
+// so a monitor keeps running when the endpoint is down.
+func notify(c *cli.Context, n *notification) {
+	url := c.GlobalString("notify-url")
+	if url == "" {
+		return
+	}
+	b, err := json.Marshal(n)
+	checkErr(err)
+	resp, err := notifyClient.Post(url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: notifying %s: %s\n", url, err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		fmt.Fprintf(os.Stderr, "Warning: notifying %s: %s\n", url, resp.Status)
+	}
+}
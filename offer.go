@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+func offerCreate(c *cli.Context) {
+	if c.String("gets") == "" || c.String("pays") == "" || !haveSigner(c) {
+		fmt.Println("Taker gets, taker pays and seed are required")
+		os.Exit(1)
+	}
+	tx := txlib.NewOfferCreate(*parseAmount(c.String("gets")), *parseAmount(c.String("pays")))
+	if c.IsSet("replace") {
+		// The old offer is cancelled in the same transaction.
+		tx.OfferSequence = new(uint32)
+		*tx.OfferSequence = uint32(c.Int("replace"))
+	}
+
+	if c.Bool("ioc") && c.Bool("fok") {
+		fmt.Println("Only one of --ioc and --fok can be used")
+		os.Exit(1)
+	}
+	if c.Bool("passive") {
+		*tx.Flags = *tx.Flags | data.TxPassive
+	}
+	if c.Bool("ioc") {
+		*tx.Flags = *tx.Flags | data.TxImmediateOrCancel
+	}
+	if c.Bool("fok") {
+		*tx.Flags = *tx.Flags | data.TxFillOrKill
+	}
+	if c.Bool("sell") {
+		*tx.Flags = *tx.Flags | data.TxSell
+	}
+	tx.Expiration = parseExpires(c)
+	sign(c, tx)
+	outputTx(c, tx)
+}
+
+// parseExpires returns the --expires time, or nil if it is not set.
+func parseExpires(c *cli.Context) *uint32 {
+	if c.String("expires") == "" {
+		return nil
+	}
+	t, err := txlib.ParseRippleTime(c.String("expires"))
+	checkErr(err)
+	return &t
+}
+
+func offerCancel(c *cli.Context) {
+	if c.Args().First() == "" || !haveSigner(c) {
+		fmt.Println("Offer sequence and seed are required")
+		os.Exit(1)
+	}
+	sequence, err := strconv.ParseUint(c.Args().First(), 10, 32)
+	checkErr(err)
+	tx := txlib.NewOfferCancel(uint32(sequence))
+	sign(c, tx)
+	outputTx(c, tx)
+}
+
+// gridAmount returns value of the asset given as on the command line,
+// rounded to what an amount can hold.
+func gridAmount(value float64, asset string) *data.Amount {
+	// Fifteen significant digits fit an issued amount and hide the float
+	// error, XRP has at most six decimal places.
+	value, err := strconv.ParseFloat(strconv.FormatFloat(value, 'g', 15, 64), 64)
+	checkErr(err)
+	s := strconv.FormatFloat(value, 'f', -1, 64)
+	if asset == "XRP" {
+		s = strconv.FormatFloat(math.Round(value*1e6)/1e6, 'f', -1, 64)
+	}
+	return parseAmount(s + "/" + asset)
+}
+
+type accountOffer struct {
+	Sequence  uint32      `json:"seq"`
+	TakerGets data.Amount `json:"taker_gets"`
+	TakerPays data.Amount `json:"taker_pays"`
+}
+
+// marketOffers returns the sequences of the account's offers trading
+// between the two assets, in either direction.
+func marketOffers(c *cli.Context, account data.Account, base, quote *data.Asset) []uint32 {
+	var sequences []uint32
+	var marker interface{}
+	for {
+		params := map[string]interface{}{
+			"account":      account.String(),
+			"ledger_index": "validated",
+		}
+		if marker != nil {
+			params["marker"] = marker
+		}
+		var result struct {
+			Offers []accountOffer `json:"offers"`
+			Marker interface{}    `json:"marker"`
+		}
+		checkErr(request(c, "account_offers", params, &result))
+		for _, offer := range result.Offers {
+			if (base.Matches(&offer.TakerGets) && quote.Matches(&offer.TakerPays)) ||
+				(quote.Matches(&offer.TakerGets) && base.Matches(&offer.TakerPays)) {
+				sequences = append(sequences, offer.Sequence)
+			}
+		}
+		if marker = result.Marker; marker == nil {
+			return sequences
+		}
+	}
+}
+
+// offerGrid signs a ladder of --count bids below and asks above the mid
+// price, --step apart, each for --size of the base asset. With --cancel the
+// account's offers in the market are cancelled first. The transactions are
+// numbered from --sequence or the account's next sequence.
+func offerGrid(c *cli.Context) {
+	if len(c.Args()) != 2 || c.String("size") == "" || c.String("step") == "" || !haveSigner(c) {
+		fmt.Println("The base and quote assets, --size, --step and seed are required")
+		os.Exit(1)
+	}
+	baseArg, quoteArg := c.Args()[0], c.Args()[1]
+	base, quote := parseAsset(baseArg), parseAsset(quoteArg)
+	size, err := strconv.ParseFloat(c.String("size"), 64)
+	checkErr(err)
+	mid := c.Float64("mid")
+	if !c.IsSet("mid") {
+		mid = midPrice(c, base, quote)
+		fmt.Fprintf(os.Stderr, "Mid price: %g %s per %s\n", mid, quoteArg, baseArg)
+	}
+	step, err := strconv.ParseFloat(strings.TrimSuffix(c.String("step"), "%"), 64)
+	checkErr(err)
+	if strings.HasSuffix(c.String("step"), "%") {
+		step = mid * step / 100
+	}
+	if size <= 0 || step <= 0 || mid <= 0 {
+		checkErr(fmt.Errorf("--size, --step and the mid price must be positive"))
+	}
+
+	var txs []data.Transaction
+	if c.Bool("cancel") {
+		for _, sequence := range marketOffers(c, *keyAccount(c), base, quote) {
+			txs = append(txs, txlib.NewOfferCancel(sequence))
+		}
+	}
+	expires := parseExpires(c)
+	for i := 1; i <= c.Int("count"); i++ {
+		bid, ask := mid-step*float64(i), mid+step*float64(i)
+		if bid <= 0 {
+			checkErr(fmt.Errorf("Level %d bid of %g is not a price, use a smaller --step or --count", i, bid))
+		}
+		// A bid sells the quote asset to buy the base, an ask the reverse.
+		for _, offer := range []*data.OfferCreate{
+			txlib.NewOfferCreate(*gridAmount(size*bid, quoteArg), *gridAmount(size, baseArg)),
+			txlib.NewOfferCreate(*gridAmount(size, baseArg), *gridAmount(size*ask, quoteArg)),
+		} {
+			offer.Expiration = expires
+			txs = append(txs, offer)
+		}
+	}
+
+	sequence := nextSequence(c)
+	for i, tx := range txs {
+		tx.GetBase().Sequence = sequence + uint32(i)
+		sign(c, tx)
+		outputTx(c, tx)
+	}
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/ripple/websockets"
+	"golang.org/x/term"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	// outputYAML is set by --output yaml.
+	outputYAML bool
+	// outputTable is set by --output table.
+	outputTable bool
+	// colorOutput is set by --color, or for auto when stdout is a terminal.
+	colorOutput bool
+)
+
+// checkOutputFormat rejects unknown --output formats before any command
+// runs. YAML is printed wherever --json would print JSON, so it sets
+// --json too.
+func checkOutputFormat(c *cli.Context) error {
+	switch c.GlobalString("output") {
+	case "", "full-json":
+	case "table":
+		outputTable = true
+	case "yaml":
+		outputYAML = true
+		if err := c.GlobalSet("json", "true"); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("Unknown output format %s, use full-json, table or yaml", c.GlobalString("output"))
+	}
+	switch c.GlobalString("color") {
+	case "auto":
+		colorOutput = term.IsTerminal(int(os.Stdout.Fd())) && os.Getenv("NO_COLOR") == ""
+	case "always":
+		colorOutput = true
+	case "never":
+		colorOutput = false
+	default:
+		return fmt.Errorf("Unknown color setting %s, use auto, always or never", c.GlobalString("color"))
+	}
+	return nil
+}
+
+// colorResult returns a transaction result, in green if it succeeded, red
+// if it failed or was claimed, and yellow if it may yet succeed, when
+// colorOutput is set.
+func colorResult(r data.TransactionResult) string {
+	s := r.String()
+	if !colorOutput {
+		return s
+	}
+	switch {
+	case r.Success():
+		return "\x1b[32m" + s + "\x1b[0m"
+	case strings.HasPrefix(s, "ter") || r.Queued():
+		return "\x1b[33m" + s + "\x1b[0m"
+	default:
+		return "\x1b[31m" + s + "\x1b[0m"
+	}
+}
+
+// printTable prints rows aligned in columns under a header. Color codes
+// would upset the alignment, so only the last column may be colored.
+func printTable(header []string, rows [][]string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	checkErr(w.Flush())
+}
+
+// printJSON prints a JSON document on one line, or with --output yaml as a
+// YAML document. Every YAML document starts with --- so that commands
+// printing several, like watch, still give a valid stream.
+func printJSON(b []byte) {
+	os.Stdout.Write(formatJSON(b))
+}
+
+// formatJSON returns a JSON document as printJSON prints it.
+func formatJSON(b []byte) []byte {
+	if !outputYAML {
+		return append(b, '\n')
+	}
+	y, err := yaml.JSONToYAML(b)
+	checkErr(err)
+	return append([]byte("---\n"), y...)
+}
+
+// fullOutput is the object printed by --output full-json. The engine result
+// is the server's preliminary result, the validated result is final.
+type fullOutput struct {
+	Hash                *data.Hash256           `json:"hash,omitempty"`
+	SigningHash         *data.Hash256           `json:"signing_hash,omitempty"`
+	TxBlob              string                  `json:"tx_blob"`
+	Tx                  json.RawMessage         `json:"tx_json"`
+	EngineResult        *data.TransactionResult `json:"engine_result,omitempty"`
+	EngineResultMessage string                  `json:"engine_result_message,omitempty"`
+	Validated           *bool                   `json:"validated,omitempty"`
+	ValidatedResult     *data.TransactionResult `json:"validated_result,omitempty"`
+	LedgerIndex         uint32                  `json:"ledger_index,omitempty"`
+}
+
+// txJSON returns the JSON of a transaction. The hash of an unsigned
+// transaction is not its id, so it is left out.
+func txJSON(c *cli.Context, tx data.Transaction) []byte {
+	b, err := json.Marshal(tx)
+	checkErr(err)
+	if !c.GlobalBool("unsigned") {
+		return b
+	}
+	var fields map[string]interface{}
+	checkErr(json.Unmarshal(b, &fields))
+	delete(fields, "hash")
+	b, err = json.Marshal(fields)
+	checkErr(err)
+	return b
+}
+
+// fullJSON returns a transaction as a single --output full-json object.
+// With --submit it waits for the transaction to be validated or to expire,
+// so the object says whether it made it into the ledger.
+func fullJSON(c *cli.Context, tx data.Transaction) []byte {
+	hash, raw, err := data.Raw(tx)
+	checkErr(err)
+	out := fullOutput{TxBlob: fmt.Sprintf("%X", raw), Tx: txJSON(c, tx)}
+	if c.GlobalBool("unsigned") {
+		signingHash, _, err := data.SigningHash(tx)
+		checkErr(err)
+		out.SigningHash = &signingHash
+	} else {
+		out.Hash = &hash
+	}
+
+	if c.GlobalBool("submit") {
+		var lastLedger uint32
+		if last := tx.GetBase().LastLedgerSequence; last != nil {
+			lastLedger = *last
+		}
+		out.await(c, hash, lastLedger, submitResult(c, tx))
+	}
+	b, err := json.Marshal(out)
+	checkErr(err)
+	return b
+}
+
+// await sets the engine result of a submission and, if it was accepted,
+// the result once the transaction is validated or expires.
+func (out *fullOutput) await(c *cli.Context, hash data.Hash256, lastLedger uint32, result *websockets.SubmitResult) {
+	out.EngineResult = &result.EngineResult
+	out.EngineResultMessage = result.EngineResultMessage
+	var validated bool
+	if result.EngineResult.Success() || result.EngineResult.Queued() {
+		txr, ledger := awaitTx(c, hash, &lastLedger)
+		finalTx(c, hash, txr, ledger)
+		out.LedgerIndex = ledger
+		if txr != nil {
+			validated = true
+			out.ValidatedResult = &txr.MetaData.TransactionResult
+		}
+	}
+	out.Validated = &validated
+}
+
+// writeFileAtomic writes b to a temporary file beside path and renames it
+// into place, so a reader sees either no file or all of it.
+func writeFileAtomic(path string, b []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// outputFile writes a transaction to --out instead of stdout, as the binary
+// blob with --binary or else as its JSON, YAML or full-json object. Only
+// the hash is printed.
+func outputFile(c *cli.Context, tx data.Transaction) {
+	if c.GlobalBool("unsigned") && c.GlobalBool("submit") {
+		checkErr(fmt.Errorf("Unsigned transactions cannot be submitted"))
+	}
+	hash, raw, err := data.Raw(tx)
+	checkErr(err)
+	if c.GlobalBool("unsigned") {
+		hash, _, err = data.SigningHash(tx)
+		checkErr(err)
+	}
+	var b []byte
+	switch {
+	case c.GlobalBool("binary"):
+		b = raw
+	case c.GlobalString("output") == "full-json":
+		b = formatJSON(fullJSON(c, tx))
+	default:
+		b = formatJSON(txJSON(c, tx))
+	}
+	checkErr(writeFileAtomic(c.GlobalString("out"), b))
+
+	// full-json has already submitted it.
+	submit := c.GlobalBool("submit") && c.GlobalString("output") != "full-json"
+	if c.GlobalBool("quiet") {
+		if submit {
+			submitTx(c, tx)
+		}
+		fmt.Println(hash)
+		return
+	}
+	label := "Hash"
+	if c.GlobalBool("unsigned") {
+		label = "Signing hash"
+	}
+	fmt.Printf("%s: %s\nWritten to: %s\n", label, hash, c.GlobalString("out"))
+	if submit {
+		submitTx(c, tx)
+	}
+}
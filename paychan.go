@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+// verifyClaim checks a claim signature offline against the channel's public
+// key, as channel_verify does.
+func verifyClaim(c *cli.Context) {
+	if c.String("channel") == "" || c.String("amount") == "" || c.String("pubkey") == "" || c.String("signature") == "" {
+		fmt.Println("Channel, amount, public key and signature are required")
+		os.Exit(1)
+	}
+	channel, err := hex.DecodeString(c.String("channel"))
+	checkErr(err)
+	if len(channel) != 32 {
+		checkErr(fmt.Errorf("Channel id is %d bytes, expected 32", len(channel)))
+	}
+	drops, err := strconv.ParseUint(c.String("amount"), 10, 64)
+	checkErr(err)
+	pub, err := txlib.ParsePublicKey(c.String("pubkey"))
+	checkErr(err)
+	sig, err := hex.DecodeString(c.String("signature"))
+	checkErr(err)
+
+	ok := txlib.VerifyClaim(channel, drops, pub, sig)
+	fmt.Printf("Claim: %s\n", validity(ok))
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+// accountChannel is an open channel from account_channels. The amount and
+// balance are in drops.
+type accountChannel struct {
+	ChannelID      data.Hash256 `json:"channel_id"`
+	Account        data.Account `json:"account"`
+	Destination    data.Account `json:"destination_account"`
+	Amount         string       `json:"amount"`
+	Balance        string       `json:"balance"`
+	SettleDelay    uint32       `json:"settle_delay"`
+	PublicKey      string       `json:"public_key,omitempty"`
+	Expiration     *uint32      `json:"expiration,omitempty"`
+	CancelAfter    *uint32      `json:"cancel_after,omitempty"`
+	SourceTag      *uint32      `json:"source_tag,omitempty"`
+	DestinationTag *uint32      `json:"destination_tag,omitempty"`
+}
+
+// dropsXRP formats a drops string from the server as XRP.
+func dropsXRP(drops string) string {
+	n, err := strconv.ParseInt(drops, 10, 64)
+	checkErr(err)
+	value, err := data.NewNativeValue(n)
+	checkErr(err)
+	return value.String()
+}
+
+// paychanList lists the channels an account has open, optionally only
+// those to --dest.
+func paychanList(c *cli.Context) {
+	account := argAccount(c)
+	if account == nil {
+		fmt.Println("Account or seed is required")
+		os.Exit(1)
+	}
+	var channels []accountChannel
+	var marker interface{}
+	for {
+		params := map[string]interface{}{
+			"account":      account.String(),
+			"ledger_index": "validated",
+			"limit":        c.Int("limit"),
+		}
+		if c.String("dest") != "" {
+			params["destination_account"] = parseAccount(c.String("dest")).String()
+		}
+		if marker != nil {
+			params["marker"] = marker
+		}
+		var result struct {
+			Channels []accountChannel `json:"channels"`
+			Marker   interface{}      `json:"marker"`
+		}
+		checkErr(request(c, "account_channels", params, &result))
+		channels = append(channels, result.Channels...)
+		if marker = result.Marker; marker == nil {
+			break
+		}
+	}
+
+	if c.GlobalBool("json") {
+		outputJSON(channels)
+		return
+	}
+	expires := func(ch accountChannel) string {
+		if ch.Expiration == nil {
+			return ""
+		}
+		return data.NewRippleTime(*ch.Expiration).String()
+	}
+	if outputTable {
+		var rows [][]string
+		for _, ch := range channels {
+			rows = append(rows, []string{ch.ChannelID.String(), ch.Destination.String(), dropsXRP(ch.Amount), dropsXRP(ch.Balance), (time.Duration(ch.SettleDelay) * time.Second).String(), expires(ch)})
+		}
+		printTable([]string{"CHANNEL", "DESTINATION", "AMOUNT", "CLAIMED", "SETTLE DELAY", "EXPIRES"}, rows)
+		return
+	}
+	for _, ch := range channels {
+		fmt.Printf("%s Destination: %s Amount: %s XRP Claimed: %s XRP Settle delay: %s", ch.ChannelID, ch.Destination, dropsXRP(ch.Amount), dropsXRP(ch.Balance), time.Duration(ch.SettleDelay)*time.Second)
+		if ch.Expiration != nil {
+			fmt.Printf(" Expires: %s", expires(ch))
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+// nextSequence returns --sequence, or else the signing account's next
+// sequence, for numbering a batch of transactions.
+func nextSequence(c *cli.Context) uint32 {
+	if c.GlobalIsSet("sequence") {
+		return uint32(c.GlobalInt("sequence"))
+	}
+	info, err := newRemote(c).AccountInfo(*keyAccount(c))
+	checkErr(err)
+	return *info.AccountData.Sequence
+}
+
+// payout signs a payment for every row of a CSV file, numbering them from
+// --sequence or else the account's next sequence, and writes the hashes and
+// blobs as CSV for submitting later. A row may name a keystore key or config
+// profile to pay from instead, whose payments are numbered from its own
+// account's next sequence.
+func payout(c *cli.Context) {
+	if c.Args().First() == "" {
+		fmt.Println("CSV file is required")
+		os.Exit(1)
+	}
+	f, err := os.Open(c.Args().First())
+	checkErr(err)
+	defer f.Close()
+	payouts, err := txlib.ReadPayoutKeys(f, resolveAddress)
+	checkErr(err)
+	named := false
+	for _, p := range payouts {
+		named = named || p.Key != ""
+	}
+
+	var out io.Writer = os.Stdout
+	var buf bytes.Buffer
+	if c.String("out") != "" {
+		// Written in one step once every payment is signed.
+		out = &buf
+	}
+	w := csv.NewWriter(out)
+	header := []string{"sequence", "destination", "amount", "tag", "hash", "blob"}
+	if named {
+		// Sequences only follow on within each account.
+		header = append(header, "account")
+	}
+	checkErr(w.Write(header))
+	if c.GlobalString("account-txn-id") != "" && c.GlobalBool("unsigned") {
+		fmt.Println("--account-txn-id cannot chain unsigned payments")
+		os.Exit(1)
+	}
+	keys := newRowKeys(c)
+	for _, p := range payouts {
+		payment := p.Payment
+		keys.sign(p.Key, payment)
+		_, raw, err := data.Raw(payment)
+		checkErr(err)
+		tag, hash := "", ""
+		if payment.DestinationTag != nil {
+			tag = strconv.FormatUint(uint64(*payment.DestinationTag), 10)
+		}
+		if !payment.GetHash().IsZero() {
+			hash = payment.GetHash().String()
+		}
+		row := []string{
+			strconv.FormatUint(uint64(payment.Sequence), 10),
+			payment.Destination.String(),
+			payment.Amount.String(),
+			tag,
+			hash,
+			fmt.Sprintf("%X", raw),
+		}
+		if named {
+			row = append(row, payment.Account.String())
+		}
+		checkErr(w.Write(row))
+	}
+	w.Flush()
+	checkErr(w.Error())
+	if c.String("out") != "" {
+		checkErr(writeFileAtomic(c.String("out"), buf.Bytes()))
+	}
+}
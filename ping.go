@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/gorilla/websocket"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+// Server states in which a server is in sync with the network
+var syncedStates = map[string]bool{"full": true, "proposing": true, "validating": true}
+
+type pingResult struct {
+	Server          string        `json:"server"`
+	Healthy         bool          `json:"healthy"`
+	Latency         time.Duration `json:"latency_ns,omitempty"`
+	State           string        `json:"server_state,omitempty"`
+	ValidatedLedger uint32        `json:"validated_ledger,omitempty"`
+	LedgerAge       time.Duration `json:"ledger_age_ns,omitempty"`
+	Error           string        `json:"error,omitempty"`
+}
+
+// pingServer times a ping to the server and checks it is in sync with a
+// recent validated ledger.
+func pingServer(url string, maxAge time.Duration) *pingResult {
+	result := &pingResult{Server: url}
+	err := func() error {
+		ws, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			return err
+		}
+		defer ws.Close()
+		start := time.Now()
+		if err := connCommand(ws, "ping", nil, &struct{}{}); err != nil {
+			return err
+		}
+		result.Latency = time.Since(start)
+		var state serverStateResult
+		if err := connCommand(ws, "server_state", nil, &state); err != nil {
+			return err
+		}
+		result.State = state.State.ServerState
+		ledger := state.State.ValidatedLedger
+		result.ValidatedLedger = ledger.Seq
+		if ledger.Seq == 0 {
+			return fmt.Errorf("no validated ledger")
+		}
+		closed := time.Unix(int64(ledger.CloseTime)+txlib.RippleEpoch, 0)
+		result.LedgerAge = time.Since(closed).Truncate(time.Second)
+		if !syncedStates[result.State] {
+			return fmt.Errorf("not in sync")
+		}
+		if result.LedgerAge > maxAge {
+			return fmt.Errorf("validated ledger is %s old", result.LedgerAge)
+		}
+		return nil
+	}()
+	if err != nil {
+		result.Error = err.Error()
+	}
+	result.Healthy = err == nil
+	return result
+}
+
+// ping checks each --server and exits 1 if any is unreachable, out of sync
+// or has no recent validated ledger.
+func ping(c *cli.Context) {
+	var results []*pingResult
+	healthy := true
+	for _, url := range strings.Split(c.GlobalString("server"), ",") {
+		result := pingServer(strings.TrimSpace(url), c.Duration("max-age"))
+		healthy = healthy && result.Healthy
+		results = append(results, result)
+	}
+
+	status := func(r *pingResult) string {
+		if r.Healthy {
+			return "ok"
+		}
+		return r.Error
+	}
+	switch {
+	case c.GlobalBool("json"):
+		outputJSON(results)
+	case outputTable:
+		var rows [][]string
+		for _, r := range results {
+			rows = append(rows, []string{r.Server, r.Latency.Round(time.Millisecond).String(), r.State, fmt.Sprint(r.ValidatedLedger), r.LedgerAge.String(), status(r)})
+		}
+		printTable([]string{"SERVER", "LATENCY", "STATE", "LEDGER", "AGE", "STATUS"}, rows)
+	default:
+		for _, r := range results {
+			if r.Latency == 0 {
+				fmt.Printf("%s: %s\n", r.Server, status(r))
+				continue
+			}
+			fmt.Printf("%s: %s latency %s state %s ledger %d age %s\n", r.Server, status(r), r.Latency.Round(time.Millisecond), r.State, r.ValidatedLedger, r.LedgerAge)
+		}
+	}
+	if !healthy {
+		os.Exit(1)
+	}
+}
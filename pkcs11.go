@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/miekg/pkcs11"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+// The DER encoded object identifier of secp256k1, as found in CKA_EC_PARAMS.
+var secp256k1OID = []byte{0x06, 0x05, 0x2B, 0x81, 0x04, 0x00, 0x0A}
+
+// pkcs11Key signs with a secp256k1 key held in a PKCS#11 token.
+type pkcs11Key struct {
+	txlib.RemotePublicKey
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+	key     pkcs11.ObjectHandle
+}
+
+func findPKCS11Object(ctx *pkcs11.Ctx, session pkcs11.SessionHandle, class uint, label string) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+	if err := ctx.FindObjectsInit(session, template); err != nil {
+		return 0, err
+	}
+	objects, _, err := ctx.FindObjects(session, 2)
+	if err != nil {
+		return 0, err
+	}
+	if err := ctx.FindObjectsFinal(session); err != nil {
+		return 0, err
+	}
+	if len(objects) != 1 {
+		return 0, fmt.Errorf("Found %d EC keys labelled %s, expected 1", len(objects), label)
+	}
+	return objects[0], nil
+}
+
+// newPKCS11Key loads the module, logs in to the slot and finds the key pair
+// with the label. The public key is read from the public key object.
+func newPKCS11Key(module string, slot uint, label, pin string) (*pkcs11Key, error) {
+	ctx := pkcs11.New(module)
+	if ctx == nil {
+		return nil, fmt.Errorf("Cannot load PKCS#11 module %s", module)
+	}
+	if err := ctx.Initialize(); err != nil {
+		return nil, err
+	}
+	session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Login(session, pkcs11.CKU_USER, pin); err != nil {
+		return nil, err
+	}
+	k := &pkcs11Key{ctx: ctx, session: session}
+	if k.key, err = findPKCS11Object(ctx, session, pkcs11.CKO_PRIVATE_KEY, label); err != nil {
+		return nil, err
+	}
+	pubKey, err := findPKCS11Object(ctx, session, pkcs11.CKO_PUBLIC_KEY, label)
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := ctx.GetAttributeValue(session, pubKey, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(attrs[0].Value, secp256k1OID) {
+		return nil, fmt.Errorf("PKCS#11 key %s is not a secp256k1 key", label)
+	}
+	// The point should be wrapped in a DER octet string, but some modules
+	// return it bare.
+	point := attrs[1].Value
+	if len(point) == 0 || point[0] != 0x04 || len(point) != 65 {
+		if _, err := asn1.Unmarshal(attrs[1].Value, &point); err != nil {
+			return nil, err
+		}
+	}
+	pub, err := btcec.ParsePubKey(point)
+	if err != nil {
+		return nil, err
+	}
+	k.RemotePublicKey = pub.SerializeCompressed()
+	return k, nil
+}
+
+// Sign uses CKM_ECDSA, which signs the hash as given and returns r and s
+// concatenated. They are DER encoded with a low S for the ledger.
+func (k *pkcs11Key) Sign(hash, msg []byte) ([]byte, error) {
+	if err := k.ctx.SignInit(k.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)}, k.key); err != nil {
+		return nil, err
+	}
+	rs, err := k.ctx.Sign(k.session, hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(rs) != 64 {
+		return nil, fmt.Errorf("Unexpected PKCS#11 signature length: %d", len(rs))
+	}
+	var r, s btcec.ModNScalar
+	r.SetByteSlice(rs[:32])
+	s.SetByteSlice(rs[32:])
+	return ecdsa.NewSignature(&r, &s).Serialize(), nil
+}
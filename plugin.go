@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plugin"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+// loadPlugins opens the Go plugins listed in TX_PLUGINS, separated like
+// PATH. Each registers its builders with txlib.Register when it is loaded.
+// The environment is read directly as the commands must exist before the
+// flags are parsed.
+func loadPlugins() {
+	for _, path := range filepath.SplitList(os.Getenv("TX_PLUGINS")) {
+		if path == "" {
+			continue
+		}
+		if _, err := plugin.Open(path); err != nil {
+			checkErr(fmt.Errorf("Plugin %s: %s", path, err))
+		}
+	}
+}
+
+func builderFlags(b *txlib.Builder) []cli.Flag {
+	var flags []cli.Flag
+	for _, f := range b.Flags {
+		if f.Bool {
+			flags = append(flags, cli.BoolFlag{Name: f.Name, Usage: f.Usage})
+		} else {
+			flags = append(flags, cli.StringFlag{Name: f.Name, Value: f.Value, Usage: f.Usage})
+		}
+	}
+	return flags
+}
+
+// build signs and outputs the transaction of a registered builder.
+func build(c *cli.Context, b *txlib.Builder) {
+	if !haveSigner(c) {
+		fmt.Println("Seed is required")
+		os.Exit(1)
+	}
+	tx, err := b.Build(c)
+	checkErr(err)
+	sign(c, tx)
+	outputTx(c, tx)
+}
+
+// builderCommands adds a command for each registered builder, with its
+// aliases. Builders may not replace the built in commands or their aliases,
+// such as whoami's derive.
+func builderCommands(commands []cli.Command) []cli.Command {
+	names := make(map[string]bool)
+	for _, command := range commands {
+		names[command.Name] = true
+		names[command.ShortName] = true
+		for _, alias := range command.Aliases {
+			names[alias] = true
+		}
+	}
+	for _, b := range txlib.Builders() {
+		for _, name := range append([]string{b.Name}, b.Aliases...) {
+			if names[name] {
+				checkErr(fmt.Errorf("Builder %s conflicts with the command or alias %s", b.Name, name))
+			}
+			names[name] = true
+		}
+		b := b
+		commands = append(commands, cli.Command{
+			Name:    b.Name,
+			Aliases: b.Aliases,
+			Usage:   b.Usage,
+			Flags:   builderFlags(b),
+			Action:  func(c *cli.Context) { build(c, b) },
+		})
+	}
+	return commands
+}
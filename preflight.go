@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/ripple/websockets"
+)
+
+// The library has no constant for deposit authorization.
+const lsfDepositAuth data.LedgerEntryFlag = 0x01000000
+
+// checkFailed stops before a payment that would fail or lose funds, unless
+// --force turns the check into a warning.
+func checkFailed(c *cli.Context, format string, a ...interface{}) {
+	if c.Bool("force") {
+		fmt.Fprintf(os.Stderr, "Warning: "+format+"\n", a...)
+		return
+	}
+	fmt.Printf(format+", use --force to send anyway\n", a...)
+	os.Exit(1)
+}
+
+// checkTrustLine makes sure the destination trusts the issuer for enough
+// of the currency to receive the amount.
+func checkTrustLine(c *cli.Context, r *websockets.Remote, payment *data.Payment) {
+	amount := payment.Amount
+	lines, err := r.AccountLines(payment.Destination, "validated")
+	checkErr(err)
+	for _, line := range lines.Lines {
+		if line.Account != amount.Issuer || line.Currency != amount.Currency {
+			continue
+		}
+		room, err := line.Limit.Subtract(line.Balance.Value)
+		checkErr(err)
+		if room.Less(*amount.Value) {
+			checkFailed(c, "%s can only receive %s more %s from %s", payment.Destination, room, amount.Currency, amount.Issuer)
+		}
+		return
+	}
+	checkFailed(c, "%s has no trust line for %s from %s", payment.Destination, amount.Currency, amount.Issuer)
+}
+
+// preflight checks the destination of a payment before it is signed. A
+// destination that does not exist yet can only be sent XRP.
+func preflight(c *cli.Context, payment *data.Payment) {
+	r := newRemote(c)
+	info, err := r.AccountInfo(payment.Destination)
+	if e, ok := err.(*websockets.CommandError); ok && e.Name == "actNotFound" {
+		if !payment.Amount.IsNative() {
+			checkFailed(c, "%s does not exist to hold %s", payment.Destination, payment.Amount.Currency)
+		}
+		return
+	}
+	checkErr(err)
+	var flags data.LedgerEntryFlag
+	if info.AccountData.Flags != nil {
+		flags = *info.AccountData.Flags
+	}
+
+	if flags&data.LsRequireDestTag != 0 && payment.DestinationTag == nil {
+		checkFailed(c, "%s requires a destination tag", payment.Destination)
+	}
+	if !payment.Amount.IsNative() && payment.Amount.Issuer != payment.Destination {
+		checkTrustLine(c, r, payment)
+	}
+	if flags&lsfDepositAuth != 0 {
+		var result struct {
+			DepositAuthorized bool `json:"deposit_authorized"`
+		}
+		source := keyAccount(c)
+		checkErr(request(c, "deposit_authorized", map[string]interface{}{
+			"source_account":      source,
+			"destination_account": payment.Destination,
+		}, &result))
+		if !result.DepositAuthorized {
+			checkFailed(c, "%s requires deposit authorization and has not preauthorized %s", payment.Destination, source)
+		}
+	}
+}
+
+// checkSequence warns when --sequence is not the account's next sequence,
+// which the server would reject with tefPAST_SEQ or hold with terPRE_SEQ.
+// It is only checked when a server is configured or the transaction is to
+// be submitted.
+func checkSequence(c *cli.Context, account data.Account, sequence uint32) error {
+	if !c.GlobalIsSet("server") && !c.GlobalBool("submit") {
+		return nil
+	}
+	r, err := websockets.NewRemote(server(c))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	info, err := r.AccountInfo(account)
+	if e, ok := err.(*websockets.CommandError); ok && e.Name == "actNotFound" {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	switch next := *info.AccountData.Sequence; {
+	case sequence < next:
+		fmt.Fprintf(os.Stderr, "Warning: sequence %d has already been used by %s, the next is %d\n", sequence, account, next)
+	case sequence > next:
+		fmt.Fprintf(os.Stderr, "Warning: sequence %d leaves a gap after %s's next sequence %d\n", sequence, account, next)
+	}
+	return nil
+}
+
+// checkNetworkID checks the server is on the --network-id network before a
+// transaction is submitted to it.
+func checkNetworkID(c *cli.Context) error {
+	if !c.GlobalIsSet("network-id") {
+		return nil
+	}
+	var info struct {
+		Info struct {
+			NetworkID int `json:"network_id"`
+		} `json:"info"`
+	}
+	if err := request(c, "server_info", nil, &info); err != nil {
+		return err
+	}
+	if info.Info.NetworkID != c.GlobalInt("network-id") {
+		return fmt.Errorf("%s is on network %d, not --network-id %d", server(c), info.Info.NetworkID, c.GlobalInt("network-id"))
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codegangsta/cli"
+	"github.com/skip2/go-qrcode"
+)
+
+// printQR renders s as a QR code in the terminal with --qr, and writes it as
+// a PNG to --qr-png. The terminal code goes to stderr when stdout carries
+// JSON or binary output.
+func printQR(c *cli.Context, s string) {
+	if !c.GlobalBool("qr") && c.GlobalString("qr-png") == "" {
+		return
+	}
+	q, err := qrcode.New(s, qrcode.Low)
+	checkErr(err)
+	if c.GlobalBool("qr") {
+		out := os.Stdout
+		if c.GlobalBool("json") || c.GlobalBool("binary") {
+			out = os.Stderr
+		}
+		fmt.Fprint(out, q.ToSmallString(false))
+	}
+	if path := c.GlobalString("qr-png"); path != "" {
+		// Eight pixels a module, whatever the size of the code.
+		checkErr(q.WriteFile(-8, path))
+	}
+}
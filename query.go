@@ -0,0 +1,281 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/gorilla/websocket"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/ripple/websockets"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+// request sends a single command to the server and decodes its result into
+// result. It is used for the commands websockets.Remote does not implement.
+// Server errors are returned as a *websockets.CommandError.
+func request(c *cli.Context, command string, params map[string]interface{}, result interface{}) error {
+	ws, _, err := websocket.DefaultDialer.Dial(server(c), nil)
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+	return connCommand(ws, command, params, result)
+}
+
+// connCommand sends a command on an open connection and decodes its result.
+func connCommand(ws *websocket.Conn, command string, params map[string]interface{}, result interface{}) error {
+	msg := map[string]interface{}{"id": 1, "command": command}
+	for k, v := range params {
+		msg[k] = v
+	}
+	if err := ws.WriteJSON(msg); err != nil {
+		return err
+	}
+
+	var response struct {
+		websockets.CommandError
+		Status string          `json:"status"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := ws.ReadJSON(&response); err != nil {
+		return err
+	}
+	if response.Status != "success" {
+		return &response.CommandError
+	}
+	return json.Unmarshal(response.Result, result)
+}
+
+// keyAccount returns the account given by --account, or else the account
+// derived from the seed. It is nil if neither was given.
+func keyAccount(c *cli.Context) *data.Account {
+	if c.GlobalString("account") != "" {
+		return parseAccount(c.GlobalString("account"))
+	}
+	if key == nil {
+		return nil
+	}
+	account := txlib.KeyAccount(key, keySequence)
+	return &account
+}
+
+// argAccount returns the account given as the first argument, falling back
+// to the account derived from the seed.
+func argAccount(c *cli.Context) *data.Account {
+	if c.Args().First() != "" {
+		return parseAccount(c.Args().First())
+	}
+	return keyAccount(c)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func outputJSON(v interface{}) {
+	out, err := json.Marshal(v)
+	checkErr(err)
+	printJSON(out)
+}
+
+// formatPath returns the path in the form accepted by data.NewPath, or false
+// if it has a hop that form cannot express.
+func formatPath(path data.Path) (string, bool) {
+	hops := make([]string, len(path))
+	for i, hop := range path {
+		if hop.Account == nil && (hop.Currency == nil || hop.Issuer == nil) {
+			return "", false
+		}
+		hops[i] = hop.String()
+	}
+	return strings.Join(hops, " => "), true
+}
+
+func paths(c *cli.Context) {
+	source := keyAccount(c)
+	if c.String("from") != "" {
+		source = parseAccount(c.String("from"))
+	}
+	if c.String("dest") == "" || c.String("amount") == "" || source == nil {
+		fmt.Println("Destination, amount, and seed or source account are required")
+		os.Exit(1)
+	}
+	destination, amount := parseAccount(c.String("dest")), parseAmount(c.String("amount"))
+
+	var currencies *[]data.Currency
+	if c.String("currencies") != "" {
+		currencies = &[]data.Currency{}
+		for _, s := range strings.Split(c.String("currencies"), ",") {
+			currency, err := data.NewCurrency(strings.TrimSpace(s))
+			checkErr(err)
+			*currencies = append(*currencies, currency)
+		}
+	}
+
+	r := newRemote(c)
+	result, err := r.RipplePathFind(*source, *destination, *amount, currencies)
+	checkErr(err)
+
+	if c.GlobalBool("json") {
+		outputJSON(result)
+		return
+	}
+
+	for _, alt := range result.Alternatives {
+		var formatted []string
+		for _, path := range alt.PathsComputed {
+			if s, ok := formatPath(path); ok {
+				formatted = append(formatted, s)
+			}
+		}
+		if c.Bool("emit") {
+			fmt.Println(strings.Join(formatted, ","))
+			continue
+		}
+		fmt.Printf("Source amount: %s Quality: %s\n", alt.SrcAmount, alt.SrcAmount.Ratio(*amount))
+		for _, path := range alt.PathsComputed {
+			fmt.Printf("  %s\n", path)
+		}
+		if len(formatted) < len(alt.PathsComputed) {
+			fmt.Println("  (some paths cannot be passed to --paths and were omitted below)")
+		}
+		if len(formatted) > 0 {
+			fmt.Printf("  --sendmax %s --paths \"%s\"\n", alt.SrcAmount.Machine(), strings.Join(formatted, ","))
+		}
+	}
+}
+
+type currencyBalance struct {
+	Currency string `json:"currency"`
+	Value    string `json:"value"`
+}
+
+type gatewayBalancesResult struct {
+	Account        string                       `json:"account"`
+	Obligations    map[string]string            `json:"obligations"`
+	Balances       map[string][]currencyBalance `json:"balances"`
+	FrozenBalances map[string][]currencyBalance `json:"frozen_balances"`
+	Assets         map[string][]currencyBalance `json:"assets"`
+	LedgerIndex    uint32                       `json:"ledger_index"`
+}
+
+func printHolders(title string, holders map[string][]currencyBalance) {
+	if len(holders) == 0 {
+		return
+	}
+	accounts := make([]string, 0, len(holders))
+	for account := range holders {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+	fmt.Println(title)
+	for _, account := range accounts {
+		fmt.Printf("  %s\n", account)
+		for _, balance := range holders[account] {
+			fmt.Printf("    %s %s\n", balance.Value, balance.Currency)
+		}
+	}
+}
+
+func obligations(c *cli.Context) {
+	issuer := argAccount(c)
+	if issuer == nil {
+		fmt.Println("Issuer account or seed is required")
+		os.Exit(1)
+	}
+	params := map[string]interface{}{
+		"account":      issuer.String(),
+		"ledger_index": "validated",
+		"strict":       true,
+	}
+	if c.String("hot") != "" {
+		var hot []string
+		for _, s := range strings.Split(c.String("hot"), ",") {
+			hot = append(hot, parseAccount(strings.TrimSpace(s)).String())
+		}
+		params["hotwallet"] = hot
+	}
+
+	var result gatewayBalancesResult
+	checkErr(request(c, "gateway_balances", params, &result))
+
+	if c.GlobalBool("json") {
+		outputJSON(result)
+		return
+	}
+
+	fmt.Printf("Issuer: %s Ledger: %d\n", result.Account, result.LedgerIndex)
+	fmt.Println("Obligations:")
+	for _, currency := range sortedKeys(result.Obligations) {
+		fmt.Printf("  %s %s\n", result.Obligations[currency], currency)
+	}
+	printHolders("Hot wallets:", result.Balances)
+	printHolders("Frozen:", result.FrozenBalances)
+	printHolders("Assets:", result.Assets)
+}
+
+type noRippleCheckResult struct {
+	LedgerIndex  uint32            `json:"ledger_current_index"`
+	Problems     []string          `json:"problems"`
+	Transactions []json.RawMessage `json:"transactions"`
+}
+
+func norippleaudit(c *cli.Context) {
+	account := argAccount(c)
+	role := c.String("role")
+	if account == nil || (role != "gateway" && role != "user") {
+		fmt.Println("Account or seed, and a role of gateway or user are required")
+		os.Exit(1)
+	}
+
+	var result noRippleCheckResult
+	checkErr(request(c, "noripple_check", map[string]interface{}{
+		"account":      account.String(),
+		"role":         role,
+		"transactions": c.Bool("fix"),
+		"limit":        c.Int("limit"),
+		"ledger_index": "current",
+	}, &result))
+
+	if !c.Bool("fix") {
+		if c.GlobalBool("json") {
+			outputJSON(result.Problems)
+			return
+		}
+		if len(result.Problems) == 0 {
+			fmt.Println("No problems found")
+		}
+		for _, problem := range result.Problems {
+			fmt.Println(problem)
+		}
+		return
+	}
+
+	for _, problem := range result.Problems {
+		fmt.Fprintln(os.Stderr, problem)
+	}
+	for i, raw := range result.Transactions {
+		tx, err := txlib.ParseTransactionJSON(raw)
+		checkErr(err)
+		if key == nil {
+			outputJSON(tx)
+			continue
+		}
+		// The server numbers the suggestions from the account's current
+		// sequence, an explicit --sequence renumbers them from there.
+		if c.GlobalIsSet("sequence") {
+			tx.GetBase().Sequence = uint32(c.GlobalInt("sequence") + i)
+		}
+		sign(c, tx)
+		outputTx(c, tx)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+var placeholder = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+
+// renderTemplate replaces each {{name}} in a JSON template with its value,
+// escaped as the inside of a JSON string. Quote the placeholder for string
+// fields and leave it bare for numbers.
+func renderTemplate(template string, values map[string]string) (string, error) {
+	var missing []string
+	rendered := placeholder.ReplaceAllStringFunc(template, func(s string) string {
+		name := placeholder.FindStringSubmatch(s)[1]
+		value, ok := values[name]
+		if !ok {
+			missing = append(missing, name)
+			return s
+		}
+		b, _ := json.Marshal(value)
+		return string(b[1 : len(b)-1])
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("No value for: %s", strings.Join(missing, ", "))
+	}
+	return rendered, nil
+}
+
+func render(c *cli.Context) {
+	if c.Args().First() == "" || !haveSigner(c) {
+		fmt.Println("Template and seed are required")
+		os.Exit(1)
+	}
+	template, err := ioutil.ReadFile(c.Args().First())
+	checkErr(err)
+	values := make(map[string]string)
+	for _, set := range c.StringSlice("set") {
+		parts := strings.SplitN(set, "=", 2)
+		if len(parts) != 2 {
+			checkErr(fmt.Errorf("Bad --set, expected name=value: %s", set))
+		}
+		values[parts[0]] = parts[1]
+	}
+	rendered, err := renderTemplate(string(template), values)
+	checkErr(err)
+	tx, err := txlib.ParseTransactionJSON([]byte(rendered))
+	checkErr(err)
+
+	if c.Bool("autofill") {
+		autofill(c, tx)
+	}
+	sign(c, tx)
+	outputTx(c, tx)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+type serverStateResult struct {
+	State struct {
+		ServerState     string `json:"server_state"`
+		ValidatedLedger struct {
+			BaseFee     int64  `json:"base_fee"`
+			CloseTime   uint32 `json:"close_time"`
+			Hash        string `json:"hash"`
+			ReserveBase int64  `json:"reserve_base"`
+			ReserveInc  int64  `json:"reserve_inc"`
+			Seq         uint32 `json:"seq"`
+		} `json:"validated_ledger"`
+	} `json:"state"`
+}
+
+type reserve struct {
+	Balance      *data.Value `json:"balance"`
+	OwnerCount   uint32      `json:"owner_count"`
+	BaseReserve  *data.Value `json:"base_reserve"`
+	OwnerReserve *data.Value `json:"owner_reserve"`
+	Spendable    *data.Value `json:"spendable"`
+}
+
+// fetchReserve combines the server's reserve settings with the account's
+// owner count. Spendable is what remains after both reserves, before fees.
+func fetchReserve(c *cli.Context, account data.Account) *reserve {
+	var state serverStateResult
+	checkErr(request(c, "server_state", nil, &state))
+	ledger := state.State.ValidatedLedger
+
+	info, err := newRemote(c).AccountInfo(account)
+	checkErr(err)
+	res := &reserve{Balance: info.AccountData.Balance}
+	if info.AccountData.OwnerCount != nil {
+		res.OwnerCount = *info.AccountData.OwnerCount
+	}
+
+	res.BaseReserve, err = data.NewNativeValue(ledger.ReserveBase)
+	checkErr(err)
+	res.OwnerReserve, err = data.NewNativeValue(ledger.ReserveInc * int64(res.OwnerCount))
+	checkErr(err)
+	total, err := res.BaseReserve.Add(*res.OwnerReserve)
+	checkErr(err)
+	res.Spendable, err = res.Balance.Subtract(*total)
+	checkErr(err)
+	if res.Spendable.IsNegative() {
+		res.Spendable = res.Spendable.ZeroClone()
+	}
+	return res
+}
+
+func reserveCmd(c *cli.Context) {
+	account := argAccount(c)
+	if account == nil {
+		fmt.Println("Account or seed is required")
+		os.Exit(1)
+	}
+	res := fetchReserve(c, *account)
+
+	if c.GlobalBool("json") {
+		outputJSON(res)
+		return
+	}
+	fmt.Printf("Balance: %s XRP\n", res.Balance)
+	fmt.Printf("Base reserve: %s XRP\n", res.BaseReserve)
+	fmt.Printf("Owner reserve: %s XRP (%d objects)\n", res.OwnerReserve, res.OwnerCount)
+	fmt.Printf("Spendable: %s XRP\n", res.Spendable)
+}
+
+// ledgerIndex prints the latest validated ledger index, for scripts
+// setting LastLedgerSequence or checking the network is making progress.
+func ledgerIndex(c *cli.Context) {
+	var state serverStateResult
+	checkErr(request(c, "server_state", nil, &state))
+	ledger := state.State.ValidatedLedger
+	if ledger.Seq == 0 {
+		checkErr(fmt.Errorf("%s has no validated ledger", server(c)))
+	}
+	closeTime := time.Unix(int64(ledger.CloseTime)+txlib.RippleEpoch, 0).UTC()
+	switch {
+	case c.GlobalBool("json"):
+		outputJSON(map[string]interface{}{"ledger_index": ledger.Seq, "ledger_hash": ledger.Hash, "close_time": closeTime})
+	case c.Bool("time"):
+		fmt.Printf("%d %s\n", ledger.Seq, closeTime.Format(time.RFC3339))
+	default:
+		fmt.Println(ledger.Seq)
+	}
+}
+
+// spendableAmount returns the XRP the seed's account can send in a single
+// transaction, and the fee it leaves for. The fee is the one sign will pay,
+// so a payment of the whole amount is funded.
+func spendableAmount(c *cli.Context) (*data.Amount, *data.Value) {
+	res := fetchReserve(c, *keyAccount(c))
+	fee, err := feeOption(c)
+	checkErr(err)
+	if fee == nil {
+		fee, err = data.NewNativeValue(txlib.DefaultFee)
+		checkErr(err)
+	}
+	value, err := res.Spendable.Subtract(*fee)
+	checkErr(err)
+	if value.IsNegative() || value.IsZero() {
+		checkErr(fmt.Errorf("Nothing to send: %s XRP spendable", res.Spendable))
+	}
+	return &data.Amount{Value: value}, fee
+}
+
+// checkSpendableFee fails if tx was signed with a higher fee than the
+// spendable amount left for, as when the open ledger fee rises between
+// the two requests. Submitted, it would fail with tecUNFUNDED_PAYMENT.
+func checkSpendableFee(tx data.Transaction, fee *data.Value) {
+	if base := tx.GetBase(); fee.Less(base.Fee) {
+		checkErr(fmt.Errorf("The fee rose from %s to %s XRP while signing, try again", fee, base.Fee))
+	}
+}
+
+func sweep(c *cli.Context) {
+	if c.String("dest") == "" || !haveSigner(c) {
+		fmt.Println("Destination and seed are required")
+		os.Exit(1)
+	}
+	destination, tag := parseDestination(c)
+	if *destination == *keyAccount(c) {
+		fmt.Println("Destination is the signing account")
+		os.Exit(1)
+	}
+	amount, fee := spendableAmount(c)
+	fmt.Fprintf(os.Stderr, "Sweeping %s XRP to %s\n", amount.Value, destination)
+
+	payment := txlib.NewPayment(*destination, *amount)
+	payment.DestinationTag = tag
+	// The whole balance is at stake, so it is never sent untagged to an
+	// account that needs a tag.
+	if c.Bool("check") || c.GlobalBool("submit") {
+		preflight(c, payment)
+	}
+	sign(c, payment)
+	checkSpendableFee(payment, fee)
+	outputTx(c, payment)
+}
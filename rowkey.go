@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/crypto"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+// rowKey is a key that rows of a batch sign with, and the sequence and hash
+// of its account's last transaction in the batch.
+type rowKey struct {
+	key      crypto.Key
+	sequence *uint32
+	next     uint32
+	last     *data.Hash256
+}
+
+// rowKeys signs the rows of a batch with the key each names, so one file
+// can pay from several wallets. Rows naming no key use the global key.
+type rowKeys struct {
+	c    *cli.Context
+	keys map[string]*rowKey
+}
+
+func newRowKeys(c *cli.Context) *rowKeys {
+	return &rowKeys{c: c, keys: make(map[string]*rowKey)}
+}
+
+// loadNamedKey returns the key stored under name in the keystore, or else
+// the key of the config profile of that name, given by its key-name,
+// keychain or seed-file setting.
+func loadNamedKey(c *cli.Context, name string) (crypto.Key, *uint32) {
+	var seed string
+	ed25519 := c.GlobalBool("ed25519")
+	if _, err := os.Stat(keyFilePath(c, name)); err == nil {
+		seed = loadKeyFile(c, name)
+	} else {
+		profile, err := configProfile(c, name)
+		if err != nil {
+			checkErr(fmt.Errorf("No key %s in the keystore: %s", name, err))
+		}
+		if keyName, ok := profile["key-name"].(string); ok {
+			seed = loadKeyFile(c, keyName)
+		} else if keychain, ok := profile["keychain"].(string); ok {
+			seed = loadKeychain(keychain)
+		} else if seedFile, ok := profile["seed-file"].(string); ok {
+			seed = readSeedFile(seedFile)
+		} else {
+			checkErr(fmt.Errorf("Profile %s has no key-name, keychain or seed-file setting", name))
+		}
+		if ed, ok := profile["ed25519"].(bool); ok && ed {
+			ed25519 = true
+		}
+	}
+	entropy, isEd25519, err := txlib.ParseSeed(seed)
+	checkErr(err)
+	k, sequence, err := txlib.NewKey(entropy, isEd25519 || ed25519)
+	checkErr(err)
+	return k, sequence
+}
+
+// get returns the named key, loading it and its account's next sequence the
+// first time it is used.
+func (r *rowKeys) get(name string) *rowKey {
+	if k, ok := r.keys[name]; ok {
+		return k
+	}
+	k := &rowKey{key: key, sequence: keySequence}
+	if name == "" {
+		if !haveSigner(r.c) {
+			checkErr(fmt.Errorf("A seed is required for rows that name no key"))
+		}
+		k.next = nextSequence(r.c)
+	} else {
+		if r.c.GlobalString("account") != "" {
+			checkErr(fmt.Errorf("Rows cannot name a key to sign with when --account is given"))
+		}
+		k.key, k.sequence = loadNamedKey(r.c, name)
+		info, err := newRemote(r.c).AccountInfo(txlib.KeyAccount(k.key, k.sequence))
+		checkErr(err)
+		k.next = *info.AccountData.Sequence
+	}
+	r.keys[name] = k
+	return k
+}
+
+// sign numbers tx with the next sequence of the named key's account and
+// signs it with that key. With --account-txn-id each transaction after the
+// first of an account only applies after the one before it.
+func (r *rowKeys) sign(name string, tx data.Transaction) {
+	k := r.get(name)
+	base := tx.GetBase()
+	base.Sequence = k.next
+	k.next++
+	if k.last != nil && r.c.GlobalString("account-txn-id") != "" {
+		base.AccountTxnID = k.last
+	}
+	saved, savedSequence := key, keySequence
+	key, keySequence = k.key, k.sequence
+	sign(r.c, tx)
+	key, keySequence = saved, savedSequence
+	k.last = tx.GetHash()
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/ripple/websockets"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+type signResponse struct {
+	Hash   data.Hash256     `json:"hash"`
+	TxBlob string           `json:"tx_blob"`
+	TxJSON data.Transaction `json:"tx_json"`
+}
+
+type submitResponse struct {
+	Hash                data.Hash256 `json:"hash"`
+	EngineResult        string       `json:"engine_result"`
+	EngineResultMessage string       `json:"engine_result_message"`
+}
+
+// The largest request body serve reads
+const maxRequestBytes = 1 << 20
+
+// signer serves the configured key over HTTP. Hardware and remote keys are
+// not safe for concurrent use, so signing is serialized. The next sequence
+// of each account signed for is kept, so transactions without one are
+// numbered in turn rather than all given the same.
+type signer struct {
+	c     *cli.Context
+	token string
+	mu    sync.Mutex
+	next  map[data.Account]uint32
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// authorized reports whether the bearer token matches, in constant time so
+// the token cannot be guessed a byte at a time.
+func (s *signer) authorized(header string) bool {
+	return s.token == "" || subtle.ConstantTimeCompare([]byte(header), []byte("Bearer "+s.token)) == 1
+}
+
+// signAccount is the account a transaction will be signed for.
+func (s *signer) signAccount(tx data.Transaction) data.Account {
+	if base := tx.GetBase(); s.c.GlobalString("account") == "" && !base.Account.IsZero() {
+		return base.Account
+	}
+	return *keyAccount(s.c)
+}
+
+// nextSequence returns the account's next sequence, from the last one
+// signed or else from the server. It is called with mu held.
+func (s *signer) nextSequence(account data.Account) (uint32, error) {
+	if seq, ok := s.next[account]; ok {
+		return seq, nil
+	}
+	r, err := websockets.NewRemote(server(s.c))
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	info, err := r.AccountInfo(account)
+	if err != nil {
+		return 0, err
+	}
+	if info.AccountData.Sequence == nil {
+		return 0, fmt.Errorf("No sequence for %s", account)
+	}
+	return *info.AccountData.Sequence, nil
+}
+
+// signJSON signs transaction JSON from the request body. A transaction
+// without a Sequence or TicketSequence gets the account's next sequence,
+// and one without a LastLedgerSequence expires as an autofilled one does,
+// so that track stops waiting for it.
+func (s *signer) signJSON(b []byte) (data.Transaction, error) {
+	tx, err := txlib.ParseTransactionJSON(b)
+	if err != nil {
+		return nil, err
+	}
+	var ticket struct {
+		TicketSequence *uint32
+	}
+	if err := json.Unmarshal(b, &ticket); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	base := tx.GetBase()
+	account := s.signAccount(tx)
+	if base.Sequence == 0 && ticket.TicketSequence == nil {
+		if base.Sequence, err = s.nextSequence(account); err != nil {
+			return nil, err
+		}
+	}
+	if base.LastLedgerSequence == nil && !s.c.GlobalIsSet("lastledger") {
+		var current struct {
+			LedgerCurrentIndex uint32 `json:"ledger_current_index"`
+		}
+		if err := request(s.c, "ledger_current", nil, &current); err != nil {
+			return nil, err
+		}
+		base.LastLedgerSequence = new(uint32)
+		*base.LastLedgerSequence = current.LedgerCurrentIndex + autofillLedgers
+	}
+	if err := signTx(s.c, tx); err != nil {
+		return nil, err
+	}
+	if ticket.TicketSequence == nil && base.Sequence >= s.next[account] {
+		s.next[account] = base.Sequence + 1
+	}
+	return tx, nil
+}
+
+// forgetSequence drops the account's tracked sequence after a submission
+// that did not use it up, so the next transaction asks the server again.
+func (s *signer) forgetSequence(tx data.Transaction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.next, tx.GetBase().Account)
+}
+
+func (s *signer) sign(w http.ResponseWriter, b []byte) {
+	tx, err := s.signJSON(b)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	_, raw, err := data.Raw(tx)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, signResponse{Hash: *tx.GetHash(), TxBlob: fmt.Sprintf("%X", raw), TxJSON: tx})
+}
+
+// submit sends a signed tx_blob, or signs and sends transaction JSON.
+func (s *signer) submit(w http.ResponseWriter, b []byte) {
+	var blob struct {
+		TxBlob string `json:"tx_blob"`
+	}
+	if err := json.Unmarshal(b, &blob); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var tx data.Transaction
+	var err error
+	if blob.TxBlob != "" {
+		var raw []byte
+		if raw, err = hex.DecodeString(blob.TxBlob); err == nil {
+			tx, err = data.ReadTransaction(bytes.NewReader(raw))
+		}
+	} else {
+		tx, err = s.signJSON(b)
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	hash, _, err := data.Raw(tx)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	result, err := s.send(tx)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, submitResponse{hash, result.EngineResult.String(), result.EngineResultMessage})
+}
+
+// send submits a signed transaction to the server, as submit does for the
+// command line.
+func (s *signer) send(tx data.Transaction) (*websockets.SubmitResult, error) {
+	result, err := submitSigned(s.c, tx)
+	if err != nil {
+		return nil, err
+	}
+	if !result.EngineResult.Success() && !result.EngineResult.Queued() {
+		// Only a tec result takes the sequence.
+		if !strings.HasPrefix(result.EngineResult.String(), "tec") {
+			s.forgetSequence(tx)
+		}
+		return result, nil
+	}
+	go s.track(tx)
+	return result, nil
+}
+
+// track waits for a submitted transaction to be validated or expire, to
+// count it and post it to --notify-url as wait does. A signed blob without a
+// LastLedgerSequence may never do either, so it is not waited for.
+func (s *signer) track(tx data.Transaction) {
+	hash := *tx.GetHash()
+	last := tx.GetBase().LastLedgerSequence
+	if last == nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s has no LastLedgerSequence, not waiting for it\n", hash)
+		return
+	}
+	lastLedger := *last
+	result, ledger, err := pollTx(s.c, hash, &lastLedger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: waiting for %s: %s\n", hash, err)
+		return
+	}
+	finalTx(s.c, hash, result, ledger)
+}
+
+func (s *signer) tx(w http.ResponseWriter, id string) {
+	hash, err := data.NewHash256(id)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	r, err := websockets.NewRemote(server(s.c))
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	defer r.Close()
+	result, err := r.Tx(*hash)
+	if e, ok := err.(*websockets.CommandError); ok && e.Name == "txnNotFound" {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *signer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if !s.authorized(req.Header.Get("Authorization")) {
+		writeError(w, http.StatusUnauthorized, fmt.Errorf("Missing or wrong bearer token"))
+		return
+	}
+	switch {
+	case req.Method == "GET" && strings.HasPrefix(req.URL.Path, "/tx/"):
+		s.tx(w, strings.TrimPrefix(req.URL.Path, "/tx/"))
+	case req.Method == "POST" && (req.URL.Path == "/sign" || req.URL.Path == "/submit"):
+		b, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, maxRequestBytes))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if req.URL.Path == "/sign" {
+			s.sign(w, b)
+		} else {
+			s.submit(w, b)
+		}
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("Unknown endpoint %s %s", req.Method, req.URL.Path))
+	}
+}
+
+// serve runs an HTTP signing service for the configured key.
+func serve(c *cli.Context) {
+	if key == nil {
+		fmt.Println("A seed or key is required")
+		os.Exit(1)
+	}
+	if c.String("token") == "" {
+		fmt.Fprintln(os.Stderr, "Warning: no --token, anyone who can reach the service can sign with the key")
+	}
+	serveMetrics(c)
+	fmt.Printf("Signing for %s on %s\n", keyAccount(c), c.String("listen"))
+	s := &signer{c: c, token: c.String("token"), next: make(map[data.Account]uint32)}
+	if c.String("grpc") != "" {
+		fmt.Printf("gRPC on %s\n", c.String("grpc"))
+		go func() {
+			checkErr(serveGRPC(s, c.String("grpc")))
+		}()
+	}
+	checkErr(http.ListenAndServe(c.String("listen"), s))
+}
@@ -0,0 +1,5 @@
+// Package signerpb is the gRPC interface of tx serve, generated from
+// signer.proto.
+package signerpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative signer.proto
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/ripple/websockets"
+)
+
+// bookLevel is an offer as a price in the quote asset per unit of the base
+// asset and a size in the base asset.
+type bookLevel struct {
+	Price float64
+	Size  float64
+}
+
+// depth is the size offered within Percent of the mid price.
+type depth struct {
+	Percent float64 `json:"percent"`
+	Bids    float64 `json:"bids"`
+	Asks    float64 `json:"asks"`
+}
+
+type spreadResult struct {
+	Base          string  `json:"base"`
+	Quote         string  `json:"quote"`
+	BestBid       float64 `json:"best_bid"`
+	BestAsk       float64 `json:"best_ask"`
+	Mid           float64 `json:"mid"`
+	Spread        float64 `json:"spread"`
+	SpreadPercent float64 `json:"spread_percent"`
+	Depth         []depth `json:"depth"`
+}
+
+func valueFloat(v *data.Value) float64 {
+	f, err := strconv.ParseFloat(v.String(), 64)
+	checkErr(err)
+	return f
+}
+
+// bookLevels fetches up to limit offers selling gets for pays, best first.
+// With ask set the base asset is gets, otherwise it is pays.
+func bookLevels(c *cli.Context, gets, pays *data.Asset, ask bool, limit int) []bookLevel {
+	var result websockets.BookOffersResult
+	checkErr(request(c, "book_offers", map[string]interface{}{
+		"taker_gets":   gets,
+		"taker_pays":   pays,
+		"ledger_index": "validated",
+		"limit":        limit,
+	}, &result))
+	var levels []bookLevel
+	for i := range result.Offers {
+		offer := &result.Offers[i].Offer
+		if offer.TakerGets == nil || offer.TakerPays == nil {
+			continue
+		}
+		got, paid := valueFloat(offer.TakerGets.Value), valueFloat(offer.TakerPays.Value)
+		if got == 0 || paid == 0 {
+			continue
+		}
+		if ask {
+			levels = append(levels, bookLevel{Price: paid / got, Size: got})
+		} else {
+			levels = append(levels, bookLevel{Price: got / paid, Size: paid})
+		}
+	}
+	return levels
+}
+
+// midPrice returns the price halfway between the best bid and ask for the
+// base asset.
+func midPrice(c *cli.Context, base, quote *data.Asset) float64 {
+	asks := bookLevels(c, base, quote, true, 1)
+	bids := bookLevels(c, quote, base, false, 1)
+	if len(asks) == 0 || len(bids) == 0 {
+		checkErr(fmt.Errorf("The book has no bids or no asks to take a mid price from"))
+	}
+	return (bids[0].Price + asks[0].Price) / 2
+}
+
+// spread shows the best bid and ask for the base asset, priced in the quote
+// asset, and how much is offered near the mid price.
+func spread(c *cli.Context) {
+	if len(c.Args()) != 2 {
+		fmt.Println("The base asset and the quote asset are required")
+		os.Exit(1)
+	}
+	base, quote := parseAsset(c.Args()[0]), parseAsset(c.Args()[1])
+	var percents []float64
+	for _, s := range strings.Split(c.String("levels"), ",") {
+		p, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "%"), 64)
+		checkErr(err)
+		percents = append(percents, p)
+	}
+
+	asks := bookLevels(c, base, quote, true, c.Int("limit"))
+	bids := bookLevels(c, quote, base, false, c.Int("limit"))
+	if len(asks) == 0 || len(bids) == 0 {
+		checkErr(fmt.Errorf("The book has no bids or no asks"))
+	}
+	result := spreadResult{
+		Base:    c.Args()[0],
+		Quote:   c.Args()[1],
+		BestBid: bids[0].Price,
+		BestAsk: asks[0].Price,
+	}
+	result.Mid = (result.BestBid + result.BestAsk) / 2
+	result.Spread = result.BestAsk - result.BestBid
+	result.SpreadPercent = result.Spread / result.Mid * 100
+	for _, p := range percents {
+		d := depth{Percent: p}
+		for _, bid := range bids {
+			if bid.Price >= result.Mid*(1-p/100) {
+				d.Bids += bid.Size
+			}
+		}
+		for _, ask := range asks {
+			if ask.Price <= result.Mid*(1+p/100) {
+				d.Asks += ask.Size
+			}
+		}
+		result.Depth = append(result.Depth, d)
+	}
+
+	if c.GlobalBool("json") {
+		outputJSON(result)
+		return
+	}
+	fmt.Printf("Best bid: %g Best ask: %g Mid: %g %s per %s\n", result.BestBid, result.BestAsk, result.Mid, result.Quote, result.Base)
+	fmt.Printf("Spread: %g (%.3f%%)\n", result.Spread, result.SpreadPercent)
+	for _, d := range result.Depth {
+		fmt.Printf("Within %g%%: bids %g asks %g %s\n", d.Percent, d.Bids, d.Asks, result.Base)
+	}
+	if len(bids) == c.Int("limit") || len(asks) == c.Int("limit") {
+		fmt.Fprintf(os.Stderr, "Warning: only the best %d offers on each side were counted, raise --limit for the full depth\n", c.Int("limit"))
+	}
+}
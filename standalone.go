@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/codegangsta/cli"
+)
+
+// The admin websocket port of a rippled started with --standalone
+const standaloneServer = "ws://127.0.0.1:6006"
+
+// ledgerAccept closes the current ledger of a standalone rippled, which
+// only advances when told to, and returns the index of the new open ledger.
+func ledgerAccept(c *cli.Context) (uint32, error) {
+	var result struct {
+		LedgerCurrentIndex uint32 `json:"ledger_current_index"`
+	}
+	err := request(c, "ledger_accept", nil, &result)
+	return result.LedgerCurrentIndex, err
+}
+
+func ledgerAcceptCmd(c *cli.Context) {
+	index, err := ledgerAccept(c)
+	checkErr(err)
+	if c.GlobalBool("json") {
+		outputJSON(map[string]uint32{"ledger_current_index": index})
+		return
+	}
+	fmt.Printf("Ledger %d closed, %d is open\n", index-1, index)
+}
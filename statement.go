@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+// statementLine is one change to one of the account's balances, with the
+// balance after it.
+type statementLine struct {
+	Date     time.Time    `json:"date"`
+	Ledger   uint32       `json:"ledger_index"`
+	Hash     data.Hash256 `json:"hash"`
+	Type     string       `json:"type"`
+	Currency string       `json:"currency"`
+	Debit    string       `json:"debit,omitempty"`
+	Credit   string       `json:"credit,omitempty"`
+	Balance  *data.Value  `json:"balance"`
+}
+
+// balanceName names a balance as XRP or currency/counterparty.
+func balanceName(b txlib.BalanceChange) string {
+	if b.Issuer == nil {
+		return "XRP"
+	}
+	return b.Currency.String() + "/" + b.Issuer.String()
+}
+
+// parseAccountTx reads a transaction and its metadata from account_tx.
+// Old ledgers give "unavailable" rather than a delivered amount, which is
+// dropped as it is not needed for balances.
+func parseAccountTx(rawTx, rawMeta json.RawMessage) (*data.TransactionWithMetaData, uint32, error) {
+	tx, err := txlib.ParseTransactionJSON(rawTx)
+	if err != nil {
+		return nil, 0, err
+	}
+	var fields struct {
+		Date uint32 `json:"date"`
+	}
+	if err := json.Unmarshal(rawTx, &fields); err != nil {
+		return nil, 0, err
+	}
+	var meta map[string]json.RawMessage
+	if err := json.Unmarshal(rawMeta, &meta); err != nil {
+		return nil, 0, err
+	}
+	if string(meta["delivered_amount"]) == `"unavailable"` {
+		delete(meta, "delivered_amount")
+	}
+	if rawMeta, err = json.Marshal(meta); err != nil {
+		return nil, 0, err
+	}
+	txm := &data.TransactionWithMetaData{Transaction: tx}
+	if err := json.Unmarshal(rawMeta, &txm.MetaData); err != nil {
+		return nil, 0, err
+	}
+	return txm, fields.Date, nil
+}
+
+// fetchStatement replays the account's validated history, oldest first,
+// keeping a running total of each balance. The totals are returned too.
+func fetchStatement(c *cli.Context, account data.Account) ([]statementLine, map[string]*data.Value) {
+	var lines []statementLine
+	balances := make(map[string]*data.Value)
+	var marker interface{}
+	for {
+		params := map[string]interface{}{
+			"account":          account.String(),
+			"ledger_index_min": -1,
+			"ledger_index_max": -1,
+			"forward":          true,
+			"limit":            200,
+		}
+		if marker != nil {
+			params["marker"] = marker
+		}
+		var result struct {
+			Transactions []struct {
+				Meta        json.RawMessage `json:"meta"`
+				Tx          json.RawMessage `json:"tx"`
+				LedgerIndex uint32          `json:"ledger_index"`
+			} `json:"transactions"`
+			Marker interface{} `json:"marker"`
+		}
+		checkErr(request(c, "account_tx", params, &result))
+		for _, t := range result.Transactions {
+			txm, date, err := parseAccountTx(t.Tx, t.Meta)
+			checkErr(err)
+			changes, err := txlib.BalanceChanges(txm)
+			checkErr(err)
+			for _, b := range changes {
+				if !b.Account.Equals(account) {
+					continue
+				}
+				name := balanceName(b)
+				balance := b.Change
+				if previous, ok := balances[name]; ok {
+					balance, err = previous.Add(*b.Change)
+					checkErr(err)
+				}
+				balances[name] = balance
+				line := statementLine{
+					Date:     time.Unix(int64(date)+txlib.RippleEpoch, 0).UTC(),
+					Ledger:   t.LedgerIndex,
+					Hash:     *txm.GetHash(),
+					Type:     txm.GetType(),
+					Currency: name,
+					Balance:  balance,
+				}
+				if b.Change.IsNegative() {
+					line.Debit = b.Change.Negate().String()
+				} else {
+					line.Credit = b.Change.String()
+				}
+				lines = append(lines, line)
+			}
+		}
+		if marker = result.Marker; marker == nil {
+			return lines, balances
+		}
+	}
+}
+
+// sameBalance reports whether balances agree to the precision of an issued
+// amount.
+func sameBalance(a, b string) bool {
+	x, errX := strconv.ParseFloat(a, 64)
+	y, errY := strconv.ParseFloat(b, 64)
+	return errX == nil && errY == nil && math.Abs(x-y) <= 1e-15*math.Max(math.Abs(x), math.Abs(y))
+}
+
+// reconcile warns about each replayed balance that differs from the
+// account's balance in the validated ledger, as happens when the server
+// does not have the account's full history.
+func reconcile(c *cli.Context, account data.Account, balances map[string]*data.Value) {
+	info, err := newRemote(c).AccountInfo(account)
+	checkErr(err)
+	actual := map[string]string{"XRP": info.AccountData.Balance.String()}
+	var marker interface{}
+	for {
+		params := map[string]interface{}{
+			"account":      account.String(),
+			"ledger_index": "validated",
+		}
+		if marker != nil {
+			params["marker"] = marker
+		}
+		var result struct {
+			Lines []struct {
+				Account  string `json:"account"`
+				Balance  string `json:"balance"`
+				Currency string `json:"currency"`
+			} `json:"lines"`
+			Marker interface{} `json:"marker"`
+		}
+		checkErr(request(c, "account_lines", params, &result))
+		for _, line := range result.Lines {
+			currency, err := data.NewCurrency(line.Currency)
+			checkErr(err)
+			actual[currency.String()+"/"+line.Account] = line.Balance
+		}
+		if marker = result.Marker; marker == nil {
+			break
+		}
+	}
+	for name, balance := range balances {
+		want, ok := actual[name]
+		if !ok {
+			// The trust line has since been removed.
+			want = "0"
+		}
+		if !sameBalance(balance.String(), want) {
+			fmt.Fprintf(os.Stderr, "Warning: replayed %s balance %s differs from the ledger's %s, the server may not have the account's full history\n", name, balance, want)
+		}
+	}
+}
+
+// statement lists every change to an account's balances, oldest first, with
+// the running balance of each currency after it.
+func statement(c *cli.Context) {
+	account := argAccount(c)
+	if account == nil {
+		fmt.Println("Account or seed is required")
+		os.Exit(1)
+	}
+	lines, balances := fetchStatement(c, *account)
+	reconcile(c, *account, balances)
+
+	switch {
+	case c.Bool("csv"):
+		w := csv.NewWriter(os.Stdout)
+		checkErr(w.Write([]string{"date", "ledger_index", "hash", "type", "currency", "debit", "credit", "balance"}))
+		for _, l := range lines {
+			checkErr(w.Write([]string{l.Date.Format(time.RFC3339), strconv.FormatUint(uint64(l.Ledger), 10), l.Hash.String(), l.Type, l.Currency, l.Debit, l.Credit, l.Balance.String()}))
+		}
+		w.Flush()
+		checkErr(w.Error())
+	case c.GlobalBool("json"):
+		outputJSON(lines)
+	case outputTable:
+		var rows [][]string
+		for _, l := range lines {
+			rows = append(rows, []string{l.Date.Format(time.RFC3339), l.Hash.String(), l.Type, l.Currency, l.Debit, l.Credit, l.Balance.String()})
+		}
+		printTable([]string{"DATE", "HASH", "TYPE", "CURRENCY", "DEBIT", "CREDIT", "BALANCE"}, rows)
+	default:
+		for _, l := range lines {
+			amount := "+" + l.Credit
+			if l.Debit != "" {
+				amount = "-" + l.Debit
+			}
+			fmt.Printf("%s %s %s %s %s balance %s\n", l.Date.Format(time.RFC3339), l.Hash, l.Type, amount, l.Currency, l.Balance)
+		}
+		var names []string
+		for name := range balances {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("Closing %s balance: %s\n", name, balances[name])
+		}
+	}
+}
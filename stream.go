@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/codegangsta/cli"
+	"github.com/gorilla/websocket"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/ripple/websockets"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+// subscribe sends a subscribe command and passes every message the server
+// sends back to handle with its type, starting with the "response" to the
+// command itself. It only returns when the connection fails.
+func subscribe(c *cli.Context, params map[string]interface{}, handle func(msgType string, b []byte)) error {
+	ws, _, err := websocket.DefaultDialer.Dial(server(c), nil)
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+
+	msg := map[string]interface{}{"id": 1, "command": "subscribe"}
+	for k, v := range params {
+		msg[k] = v
+	}
+	if err := ws.WriteJSON(msg); err != nil {
+		return err
+	}
+	for {
+		_, b, err := ws.ReadMessage()
+		if err != nil {
+			return err
+		}
+		var header struct {
+			websockets.CommandError
+			Type   string `json:"type"`
+			Status string `json:"status"`
+		}
+		if err := json.Unmarshal(b, &header); err != nil {
+			return err
+		}
+		if header.Type == "response" && header.Status != "success" {
+			return &header.CommandError
+		}
+		handle(header.Type, b)
+	}
+}
+
+// printWatched summarises a transaction with what it did to the watched
+// accounts.
+func printWatched(msg *websockets.TransactionStreamMsg, watched map[data.Account]bool) {
+	txm := &msg.Transaction
+	fmt.Printf("%s %s %s in ledger %d\n", txm.GetHash(), txm.GetType(), colorResult(msg.EngineResult), msg.LedgerSequence)
+	if payment, ok := txm.Transaction.(*data.Payment); ok && watched[payment.Destination] {
+		checkDelivered(txm)
+	}
+	balances, err := txlib.BalanceChanges(txm)
+	checkErr(err)
+	for _, b := range balances {
+		if watched[b.Account] {
+			fmt.Printf("  %s %s\n", b.Account, b)
+		}
+	}
+}
+
+// watch prints each validated transaction affecting the accounts as it
+// arrives, as a summary or the server's JSON, one message per line.
+func watch(c *cli.Context) {
+	if len(c.Args()) == 0 {
+		fmt.Println("At least one account is required")
+		os.Exit(1)
+	}
+	watched := make(map[data.Account]bool)
+	var accounts []string
+	for _, arg := range c.Args() {
+		account := parseAccount(arg)
+		watched[*account] = true
+		accounts = append(accounts, account.String())
+	}
+	serveMetrics(c)
+	checkErr(subscribe(c, map[string]interface{}{"accounts": accounts}, func(msgType string, b []byte) {
+		if msgType != "transaction" {
+			return
+		}
+		var msg websockets.TransactionStreamMsg
+		checkErr(json.Unmarshal(b, &msg))
+		if !msg.Validated {
+			return
+		}
+		validatedTotal.Inc()
+		if !msg.EngineResult.Success() {
+			failedTotal.Inc()
+		}
+		notify(c, newNotification(&msg.Transaction, msg.LedgerSequence))
+		if c.GlobalBool("json") {
+			printJSON(b)
+			return
+		}
+		printWatched(&msg, watched)
+	}))
+}
+
+// ledgers prints each ledger as it closes, as a summary or the server's
+// JSON, one message per line.
+func ledgers(c *cli.Context) {
+	serveMetrics(c)
+	checkErr(subscribe(c, map[string]interface{}{"streams": []string{"ledger"}}, func(msgType string, b []byte) {
+		if msgType != "ledgerClosed" {
+			return
+		}
+		if c.GlobalBool("json") {
+			printJSON(b)
+			return
+		}
+		var msg websockets.LedgerStreamMsg
+		checkErr(json.Unmarshal(b, &msg))
+		fmt.Printf("%d %s %s Transactions: %d Base fee: %d Reserve: %d + %d per object\n",
+			msg.LedgerSequence, msg.LedgerHash, msg.LedgerTime.Short(), msg.TxnCount, msg.FeeBase, msg.ReserveBase, msg.ReserveIncrement)
+	}))
+}
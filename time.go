@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+func printTimes(tx data.Transaction) {
+	times, err := txlib.TxTimes(tx)
+	checkErr(err)
+	for _, name := range txlib.TimeFields {
+		if t, ok := times[name]; ok {
+			fmt.Printf("%s: %s\n", name, t)
+		}
+	}
+}
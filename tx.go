@@ -7,11 +7,15 @@ import (
 	"io/ioutil"
 	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/codegangsta/cli"
+	"github.com/gorilla/websocket"
 	"github.com/rubblelabs/ripple/crypto"
 	"github.com/rubblelabs/ripple/data"
 	"github.com/rubblelabs/ripple/websockets"
+	"github.com/rubblelabs/tx/txlib"
 )
 
 func checkErr(err error) {
@@ -21,9 +25,9 @@ func checkErr(err error) {
 	}
 }
 
+// parseAccount parses a classic address or an X-address, ignoring any tag.
 func parseAccount(s string) *data.Account {
-	account, err := data.NewAccountFromAddress(s)
-	checkErr(err)
+	account, _ := parseAddress(s)
 	return account
 }
 
@@ -44,34 +48,228 @@ func parsePaths(s string) *data.PathSet {
 }
 
 func sign(c *cli.Context, tx data.Transaction) {
+	checkErr(signTx(c, tx))
+}
+
+// signOptions gives the fields sign fills in from the global flags.
+func signOptions(c *cli.Context) (txlib.Options, error) {
+	opts := txlib.Options{
+		Sequence:           uint32(c.GlobalInt("sequence")),
+		LastLedgerSequence: uint32(c.GlobalInt("lastledger")),
+		NoCanonical:        c.GlobalBool("no-canonical"),
+	}
+	// The key signs for its own account, unless it is the regular key of
+	// the --account or of an account already set on the transaction.
+	if c.GlobalString("account") != "" {
+		opts.Account = keyAccount(c)
+	}
+	fee, err := feeOption(c)
+	opts.Fee = fee
+	return opts, err
+}
+
+// feeOption is the fee sign replaces a transaction's fee with, or nil to
+// keep it or use the default.
+func feeOption(c *cli.Context) (*data.Value, error) {
+	switch {
+	case c.GlobalIsSet("fee"):
+		return data.NewNativeValue(int64(c.GlobalInt("fee")))
+	case c.GlobalString("fee-strategy") == "server":
+		return openLedgerFee(c)
+	case c.GlobalString("fee-strategy") != "fixed":
+		return nil, fmt.Errorf("Unknown fee strategy %s, use fixed or server", c.GlobalString("fee-strategy"))
+	}
+	return nil, nil
+}
+
+// signTx is sign returning its errors, for serve which must keep running
+// when a remote signer or the server fails.
+func signTx(c *cli.Context, tx data.Transaction) error {
+	opts, err := signOptions(c)
+	if err != nil {
+		return err
+	}
+	if err := txlib.Prepare(tx, key, keySequence, opts); err != nil {
+		return err
+	}
 	base := tx.GetBase()
-	base.Sequence = uint32(c.GlobalInt("sequence"))
-	copy(base.Account[:], key.Id(keySequence))
-	if c.GlobalInt("lastledger") > 0 {
-		base.LastLedgerSequence = new(uint32)
-		*base.LastLedgerSequence = uint32(c.GlobalInt("lastledger"))
+	if c.GlobalInt("network-id") > 1024 {
+		return fmt.Errorf("Network %d needs a NetworkID field, which the transaction encoder does not support. Use sign with --definitions", c.GlobalInt("network-id"))
 	}
-	if base.Flags == nil {
-		base.Flags = new(data.TransactionFlag)
+	if c.GlobalInt("max-fee") > 0 {
+		max, err := data.NewNativeValue(int64(c.GlobalInt("max-fee")))
+		if err != nil {
+			return err
+		}
+		if max.Less(base.Fee) {
+			return fmt.Errorf("Fee of %s XRP is more than --max-fee %d drops", base.Fee, c.GlobalInt("max-fee"))
+		}
 	}
-	if c.GlobalString("fee") != "" {
-		fee, err := data.NewNativeValue(int64(c.GlobalInt("fee")))
-		checkErr(err)
-		base.Fee = *fee
+	if c.GlobalIsSet("sequence") && base.Sequence == uint32(c.GlobalInt("sequence")) {
+		if err := checkSequence(c, base.Account, base.Sequence); err != nil {
+			return err
+		}
+	}
+	if base.AccountTxnID == nil && c.GlobalString("account-txn-id") != "" {
+		hash, err := accountTxnID(c, base.Account)
+		if err != nil {
+			return err
+		}
+		base.AccountTxnID = hash
+	}
+	if c.GlobalBool("unsigned") {
+		// Left for another signer, with the public key when it is known.
+		if key != nil {
+			txlib.SetPublicKey(tx, key, keySequence)
+		}
+		return nil
 	}
-	checkErr(data.Sign(tx, key, keySequence))
+	if err := txlib.Sign(tx, key, keySequence); err != nil {
+		return err
+	}
+	signedTotal.Inc()
+	return nil
+}
+
+// haveSigner reports whether sign can be called. Unsigned transactions
+// only need an account.
+func haveSigner(c *cli.Context) bool {
+	return key != nil || (c.GlobalBool("unsigned") && c.GlobalString("account") != "")
+}
+
+var (
+	serverOnce   sync.Once
+	chosenServer string
+)
+
+// server returns the --server url. Given a comma separated list, it is the
+// first server that accepts a connection, or else the first in the list.
+func server(c *cli.Context) string {
+	serverOnce.Do(func() {
+		urls := strings.Split(c.GlobalString("server"), ",")
+		chosenServer = strings.TrimSpace(urls[0])
+		if len(urls) == 1 {
+			return
+		}
+		for _, url := range urls {
+			url = strings.TrimSpace(url)
+			ws, _, err := websocket.DefaultDialer.Dial(url, nil)
+			if err == nil {
+				ws.Close()
+				chosenServer = url
+				return
+			}
+			fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", url, err)
+		}
+	})
+	return chosenServer
 }
 
-func submitTx(tx data.Transaction) {
-	r, err := websockets.NewRemote("wss://s-east.ripple.com:443")
+func newRemote(c *cli.Context) *websockets.Remote {
+	r, err := websockets.NewRemote(server(c))
 	checkErr(err)
-	result, err := r.Submit(tx)
+	return r
+}
+
+// submitResult submits a signed transaction and counts the submission.
+func submitResult(c *cli.Context, tx data.Transaction) *websockets.SubmitResult {
+	result, err := submitSigned(c, tx)
 	checkErr(err)
-	fmt.Printf("%s: %s\n", result.EngineResult, result.EngineResultMessage)
-	os.Exit(0)
+	return result
+}
+
+// submitSigned is submitResult returning its errors, for serve.
+func submitSigned(c *cli.Context, tx data.Transaction) (*websockets.SubmitResult, error) {
+	return submitCounted(c, func() (*websockets.SubmitResult, error) {
+		return txlib.Submit(server(c), tx)
+	})
+}
+
+// submitBlob submits a transaction signed by the definitions encoder, which
+// the data package may not be able to read.
+func submitBlob(c *cli.Context, raw []byte) (*websockets.SubmitResult, error) {
+	return submitCounted(c, func() (*websockets.SubmitResult, error) {
+		var result struct {
+			EngineResult        data.TransactionResult `json:"engine_result"`
+			EngineResultMessage string                 `json:"engine_result_message"`
+		}
+		if err := request(c, "submit", map[string]interface{}{"tx_blob": fmt.Sprintf("%X", raw)}, &result); err != nil {
+			return nil, err
+		}
+		return &websockets.SubmitResult{EngineResult: result.EngineResult, EngineResultMessage: result.EngineResultMessage}, nil
+	})
+}
+
+// submitCounted checks the network, submits and counts the submission, and
+// closes the ledger of a standalone server.
+func submitCounted(c *cli.Context, submit func() (*websockets.SubmitResult, error)) (*websockets.SubmitResult, error) {
+	if err := checkNetworkID(c); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	result, err := submit()
+	if err != nil {
+		return nil, err
+	}
+	submitSeconds.Observe(time.Since(start).Seconds())
+	submittedTotal.Inc()
+	if !result.EngineResult.Success() && !result.EngineResult.Queued() {
+		failedTotal.Inc()
+	}
+	if c.GlobalBool("standalone") {
+		// Nothing closes the ledger otherwise, so the transaction would
+		// never be validated.
+		if _, err := ledgerAccept(c); err != nil {
+			return nil, fmt.Errorf("Submitted, but closing the ledger failed: %s", err)
+		}
+	}
+	return result, nil
+}
+
+func submitTx(c *cli.Context, tx data.Transaction) {
+	printSubmitResult(c, submitResult(c, tx))
+}
+
+// printSubmitResult prints the engine result of a submission, on stderr
+// after a binary blob on stdout. With --quiet only a failure is printed,
+// and it exits 1.
+func printSubmitResult(c *cli.Context, result *websockets.SubmitResult) {
+	if !result.EngineResult.Success() && !result.EngineResult.Queued() {
+		if c.GlobalBool("quiet") {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", result.EngineResult, result.EngineResultMessage)
+			os.Exit(1)
+		}
+	}
+	w := os.Stdout
+	if c.GlobalBool("binary") && c.GlobalString("out") == "" {
+		w = os.Stderr
+	}
+	if !c.GlobalBool("quiet") {
+		fmt.Fprintf(w, "%s: %s\n", colorResult(result.EngineResult), result.EngineResultMessage)
+	}
 }
 
 func outputTx(c *cli.Context, tx data.Transaction) {
+	if c.GlobalString("out") != "" {
+		outputFile(c, tx)
+		return
+	}
+	if c.GlobalBool("unsigned") {
+		outputUnsignedTx(c, tx)
+		return
+	}
+	if c.GlobalBool("quiet") {
+		// Only the hash, once any submission has been accepted.
+		if c.GlobalBool("submit") {
+			submitTx(c, tx)
+		}
+		fmt.Println(tx.GetHash())
+		return
+	}
+	if c.GlobalString("output") == "full-json" {
+		printJSON(fullJSON(c, tx))
+		return
+	}
 	if !c.GlobalBool("json") {
 		hash, raw, err := data.Raw(tx)
 		checkErr(err)
@@ -80,6 +278,8 @@ func outputTx(c *cli.Context, tx data.Transaction) {
 			os.Stdout.Write(raw)
 		} else {
 			fmt.Printf("Hash: %s\nRaw: %X\n", hash, raw)
+			printTimes(tx)
+			printExplorerLinks(c, tx)
 		}
 	}
 
@@ -87,28 +287,81 @@ func outputTx(c *cli.Context, tx data.Transaction) {
 		// Print it in JSON
 		out, err := json.Marshal(tx)
 		checkErr(err)
-		fmt.Println(string(out))
+		printJSON(out)
 	}
 
+	_, raw, err := data.Raw(tx)
+	checkErr(err)
+	printQR(c, fmt.Sprintf("%X", raw))
+
 	if c.GlobalBool("submit") {
-		submitTx(tx)
+		submitTx(c, tx)
+	}
+}
+
+// outputUnsignedTx prints the signing hash and the encoding of a transaction
+// without its signature, for signing elsewhere.
+func outputUnsignedTx(c *cli.Context, tx data.Transaction) {
+	if c.GlobalBool("submit") {
+		checkErr(fmt.Errorf("Unsigned transactions cannot be submitted"))
+	}
+	_, raw, err := data.Raw(tx)
+	checkErr(err)
+	if c.GlobalBool("quiet") {
+		signingHash, _, err := data.SigningHash(tx)
+		checkErr(err)
+		fmt.Println(signingHash)
+		return
+	}
+	if c.GlobalString("output") == "full-json" {
+		printJSON(fullJSON(c, tx))
+		return
+	}
+	if c.GlobalBool("binary") {
+		os.Stdout.Write(raw)
+		printQR(c, fmt.Sprintf("%X", raw))
+		return
+	}
+	if !c.GlobalBool("json") {
+		signingHash, _, err := data.SigningHash(tx)
+		checkErr(err)
+		fmt.Printf("Signing hash: %s\nRaw: %X\n", signingHash, raw)
 	}
+	printJSON(txJSON(c, tx))
+	printQR(c, fmt.Sprintf("%X", raw))
 }
 
 func payment(c *cli.Context) {
 	// Validate and parse required fields
-	if c.String("dest") == "" || c.String("amount") == "" || key == nil {
-		fmt.Println("Destination, amount, and seed are required")
+	if c.String("dest") == "" || !haveSigner(c) {
+		fmt.Println("Destination, amount or --all, and seed are required")
+		os.Exit(1)
+	}
+	destination, tag, requested := parseRequest(c)
+	if (c.String("amount") == "" && requested == nil) == !c.Bool("all") {
+		fmt.Println("Destination, amount or --all, and seed are required")
 		os.Exit(1)
 	}
-	destination, amount := parseAccount(c.String("dest")), parseAmount(c.String("amount"))
+	// Paying yourself only makes sense when converting between currencies.
+	selfConvert := c.Bool("allow-self") && (c.String("sendmax") != "" || c.String("paths") != "")
+	if *destination == *keyAccount(c) && !selfConvert {
+		fmt.Println("Destination is the signing account, use --allow-self with --sendmax or --paths to convert currencies")
+		os.Exit(1)
+	}
+	var amount *data.Amount
+	var allFee *data.Value
+	switch {
+	case c.Bool("all"):
+		amount, allFee = spendableAmount(c)
+	case requested != nil:
+		amount = requested
+	default:
+		amount = parseAmount(c.String("amount"))
+	}
 
 	// Create payment and sign it
-	payment := &data.Payment{
-		Destination: *destination,
-		Amount:      *amount,
-	}
-	payment.TransactionType = data.PAYMENT
+	payment := txlib.NewPayment(*destination, *amount)
+	payment.DestinationTag = tag
 
 	if c.String("paths") != "" {
 		payment.Paths = parsePaths(c.String("paths"))
@@ -118,7 +371,6 @@ func payment(c *cli.Context) {
 		payment.SendMax = parseAmount(c.String("sendmax"))
 	}
 
-	payment.Flags = new(data.TransactionFlag)
 	if c.Bool("nodirect") {
 		*payment.Flags = *payment.Flags | data.TxNoDirectRipple
 	}
@@ -128,31 +380,40 @@ func payment(c *cli.Context) {
 	if c.Bool("limit") {
 		*payment.Flags = *payment.Flags | data.TxLimitQuality
 	}
+	if c.Bool("check") || c.GlobalBool("submit") {
+		preflight(c, payment)
+	}
 	sign(c, payment)
+	if allFee != nil {
+		checkSpendableFee(payment, allFee)
+	}
 	outputTx(c, payment)
 }
 
 func trust(c *cli.Context) {
 	// Validate and parse required fields
-	if c.String("amount") == "" || key == nil {
+	if c.String("amount") == "" || !haveSigner(c) {
 		fmt.Println("Amount and seed are required")
 		os.Exit(1)
 	}
 	amount := parseAmount(c.String("amount"))
+	if c.Bool("verify-issuer") {
+		if _, err := verifyIssuer(c, amount.Issuer); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: issuer not verified: %s\n", err)
+		}
+	}
 
 	// Create tx and sign it
-	tx := &data.TrustSet{
-		LimitAmount: *amount,
-	}
-	tx.TransactionType = data.TRUST_SET
+	tx := txlib.NewTrustSet(*amount)
 
-	tx.QualityOut = new(uint32)
-	*tx.QualityOut = uint32(c.Float64("quality-out") * 1000000000)
+	qualityOut, err := txlib.ParseQuality(c.String("quality-out"))
+	checkErr(err)
+	tx.QualityOut = &qualityOut
 
-	tx.QualityIn = new(uint32)
-	*tx.QualityIn = uint32(c.Float64("quality-in") * 1000000000)
+	qualityIn, err := txlib.ParseQuality(c.String("quality-in"))
+	checkErr(err)
+	tx.QualityIn = &qualityIn
 
-	tx.Flags = new(data.TransactionFlag)
 	if c.Bool("auth") {
 		*tx.Flags = *tx.Flags | data.TxSetAuth
 	}
@@ -168,12 +429,54 @@ func trust(c *cli.Context) {
 	if c.Bool("clear-freeze") {
 		*tx.Flags = *tx.Flags | data.TxClearFreeze
 	}
+	// A deep freeze is only allowed on a frozen trust line.
+	if c.Bool("deep-freeze") {
+		*tx.Flags = *tx.Flags | data.TxSetFreeze | txSetDeepFreeze
+	}
+	if c.Bool("clear-deep-freeze") {
+		*tx.Flags = *tx.Flags | txClearDeepFreeze
+	}
 
 	sign(c, tx)
 	outputTx(c, tx)
 }
 
+// submitFiles submits the signed transaction in each file and prints its
+// result, exiting 1 if any was not accepted.
+func submitFiles(c *cli.Context, paths []string) {
+	failed := false
+	for _, path := range paths {
+		b, err := ioutil.ReadFile(path)
+		checkErr(err)
+		tx := parseTxFile(b)
+		base := tx.GetBase()
+		if (base.TxnSignature == nil || len(*base.TxnSignature) == 0) && len(base.Signers) == 0 {
+			checkErr(fmt.Errorf("%s: transaction is not signed", path))
+		}
+		hash, _, err := data.Raw(tx)
+		checkErr(err)
+		result := submitResult(c, tx)
+		ok := result.EngineResult.Success() || result.EngineResult.Queued()
+		failed = failed || !ok
+		switch {
+		case !c.GlobalBool("quiet"):
+			fmt.Printf("%s: %s %s: %s\n", path, hash, colorResult(result.EngineResult), result.EngineResultMessage)
+		case ok:
+			fmt.Println(hash)
+		default:
+			fmt.Fprintf(os.Stderr, "%s: %s %s: %s\n", path, hash, result.EngineResult, result.EngineResultMessage)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
 func submit(c *cli.Context) {
+	if len(c.Args()) > 0 {
+		submitFiles(c, c.Args())
+		return
+	}
 	bs, err := ioutil.ReadAll(os.Stdin)
 	checkErr(err)
 
@@ -183,21 +486,121 @@ func submit(c *cli.Context) {
 	outputTx(c, tx)
 }
 
+func pkcs11PIN(c *cli.Context, prompt string) (string, error) {
+	if pin := c.GlobalString("pkcs11-pin"); pin != "" {
+		return pin, nil
+	}
+	return readSecret(prompt)
+}
+
 func common(c *cli.Context) error {
-	if c.GlobalString("seed") == "" {
-		return fmt.Errorf("No seed specified")
+	if err := loadConfig(c); err != nil {
+		return err
 	}
-	seed, err := crypto.NewRippleHashCheck(c.GlobalString("seed"), crypto.RIPPLE_FAMILY_SEED)
-	if err != nil {
+	if err := setNetwork(c); err != nil {
+		return err
+	}
+	if err := checkOutputFormat(c); err != nil {
+		return err
+	}
+	if err := setProxy(c); err != nil {
+		return err
+	}
+	if err := setTLS(c); err != nil {
 		return err
 	}
-	if c.GlobalBool("ed25519") {
-		key, err = crypto.NewEd25519Key(seed.Payload())
-	} else {
-		key, err = crypto.NewECDSAKey(seed.Payload())
-		seq := uint32(0)
-		keySequence = &seq
+	setTimeouts(c)
+	addressBookFile = c.GlobalString("addressbook")
+	if env, ok := payStringEnvironments[c.GlobalString("network")]; ok {
+		payStringEnvironment = env
+	}
+	if c.GlobalBool("ledger") {
+		path, err := txlib.ParseHDPath(c.GlobalString("hdpath"))
+		if err != nil {
+			return err
+		}
+		key, err = newLedgerKey(path)
+		return err
 	}
+	if c.GlobalBool("yubihsm") {
+		module := c.GlobalString("pkcs11-module")
+		if module == "" {
+			module = yubiHSMModule()
+		}
+		password, err := pkcs11PIN(c, "Password: ")
+		if err != nil {
+			return err
+		}
+		key, err = newYubiHSMKey(module, c.GlobalString("yubihsm-connector"), c.GlobalInt("yubihsm-auth-key"), c.GlobalString("key-label"), password)
+		return err
+	}
+	if c.GlobalString("pkcs11-module") != "" {
+		pin, err := pkcs11PIN(c, "PIN: ")
+		if err != nil {
+			return err
+		}
+		key, err = newPKCS11Key(c.GlobalString("pkcs11-module"), uint(c.GlobalInt("slot")), c.GlobalString("key-label"), pin)
+		return err
+	}
+	if c.GlobalString("aws-kms") != "" {
+		var err error
+		key, err = newAWSKMSKey(c.GlobalString("aws-kms"))
+		return err
+	}
+	if c.GlobalString("vault-transit") != "" {
+		var err error
+		key, err = newVaultTransitKey(c.GlobalString("vault-transit"))
+		return err
+	}
+	if c.GlobalString("secret-hex") != "" {
+		var err error
+		key, err = txlib.ParseSecretHex(c.GlobalString("secret-hex"), c.GlobalBool("ed25519"))
+		return err
+	}
+	if c.GlobalString("mnemonic") != "" {
+		path, err := txlib.ParseHDPath(c.GlobalString("hdpath"))
+		if err != nil {
+			return err
+		}
+		seed, err := txlib.MnemonicSeed(c.GlobalString("mnemonic"), c.GlobalString("passphrase"))
+		if err != nil {
+			return err
+		}
+		k, err := txlib.DeriveHDKey(seed, path)
+		if err != nil {
+			return err
+		}
+		key = txlib.Secp256k1Key{PrivateKey: k}
+		return nil
+	}
+	// Query commands do not need a key, so a missing seed is only
+	// reported by the commands that sign.
+	seed := c.GlobalString("seed")
+	if c.GlobalString("key-name") != "" {
+		seed = loadKeyFile(c, c.GlobalString("key-name"))
+	} else if c.GlobalString("keychain") != "" {
+		seed = loadKeychain(c.GlobalString("keychain"))
+	} else if c.GlobalString("seed-file") != "" {
+		seed = readSeedFile(c.GlobalString("seed-file"))
+	} else if c.GlobalString("vault-seed") != "" {
+		var err error
+		if seed, err = vaultSeed(c.GlobalString("vault-seed"), c.GlobalString("vault-field")); err != nil {
+			return err
+		}
+	} else if seed == "-" || c.GlobalBool("prompt") {
+		var err error
+		if seed, err = readSecret("Seed: "); err != nil {
+			return err
+		}
+	}
+	if seed == "" {
+		return nil
+	}
+	entropy, ed25519, err := txlib.ParseSeed(seed)
+	if err != nil {
+		return err
+	}
+	key, keySequence, err = txlib.NewKey(entropy, ed25519 || c.GlobalBool("ed25519"))
 	return err
 }
 
@@ -209,17 +612,71 @@ var (
 func main() {
 	app := cli.NewApp()
 	app.Name = "tx"
-	app.Usage = "create a Ripple transaction. Sequence and seed must be specified for every command."
+	app.Usage = "create a Ripple transaction. Sequence and seed must be specified for every command that signs. Global flags may also be set from TX_ environment variables, e.g. TX_SEED."
 	app.Version = "0.1"
 	app.Flags = []cli.Flag{
-		cli.StringFlag{Name: "seed,s", Value: "", Usage: "the seed for the submitting account"},
-		cli.BoolFlag{Name: "ed25519,e", Usage: "seed is for an ed25519 account"},
-		cli.IntFlag{Name: "fee,f", Value: 10, Usage: "the fee you want to pay"},
-		cli.IntFlag{Name: "sequence,q", Value: 0, Usage: "the sequence for the transaction"},
-		cli.IntFlag{Name: "lastledger,l", Value: 0, Usage: "highest ledger number that the transaction can appear in"},
-		cli.BoolFlag{Name: "submit,t", Usage: "submits the transaction via websocket"},
-		cli.BoolFlag{Name: "binary,b", Usage: "raw output in binary"},
-		cli.BoolFlag{Name: "json,j", Usage: "output only the resulting JSON"},
+		cli.StringFlag{Name: "seed,s", Value: "", Usage: "the seed for the submitting account", EnvVar: "TX_SEED"},
+		cli.BoolFlag{Name: "prompt", Usage: "read the seed from the terminal without echoing it, or from stdin when piped. Also --seed -", EnvVar: "TX_PROMPT"},
+		cli.StringFlag{Name: "key-name,k", Value: "", Usage: "sign with this key from the keystore instead of a seed", EnvVar: "TX_KEY_NAME"},
+		cli.StringFlag{Name: "seed-file", Value: "", Usage: "read the seed from a file, decrypting it with gpg if it ends .gpg, .pgp or .asc", EnvVar: "TX_SEED_FILE"},
+		cli.StringFlag{Name: "vault-seed", Value: "", Usage: "read the seed from this Vault secret, using VAULT_ADDR and VAULT_TOKEN", EnvVar: "TX_VAULT_SEED"},
+		cli.StringFlag{Name: "vault-field", Value: "seed", Usage: "the field of the Vault secret holding the seed", EnvVar: "TX_VAULT_FIELD"},
+		cli.StringFlag{Name: "vault-transit", Value: "", Usage: "sign remotely with this ed25519 Vault transit key, as mount/name", EnvVar: "TX_VAULT_TRANSIT"},
+		cli.StringFlag{Name: "aws-kms", Value: "", Usage: "sign remotely with this AWS KMS ECC_SECG_P256K1 key id, alias or ARN", EnvVar: "TX_AWS_KMS"},
+		cli.StringFlag{Name: "pkcs11-module", Value: "", Usage: "sign with a secp256k1 key in a PKCS#11 token, using this module library", EnvVar: "TX_PKCS11_MODULE"},
+		cli.IntFlag{Name: "slot", Value: 0, Usage: "the PKCS#11 slot id", EnvVar: "TX_SLOT"},
+		cli.StringFlag{Name: "key-label", Value: "", Usage: "the label of the PKCS#11 key pair", EnvVar: "TX_KEY_LABEL"},
+		cli.StringFlag{Name: "pkcs11-pin", Value: "", Usage: "the PKCS#11 user PIN or YubiHSM password, prompted for if not set", EnvVar: "TX_PKCS11_PIN"},
+		cli.BoolFlag{Name: "yubihsm", Usage: "sign with the secp256k1 --key-label key on a YubiHSM 2, using Yubico's PKCS#11 module", EnvVar: "TX_YUBIHSM"},
+		cli.StringFlag{Name: "yubihsm-connector", Value: "http://127.0.0.1:12345", Usage: "the yubihsm-connector url", EnvVar: "TX_YUBIHSM_CONNECTOR"},
+		cli.IntFlag{Name: "yubihsm-auth-key", Value: 1, Usage: "the YubiHSM authentication key id", EnvVar: "TX_YUBIHSM_AUTH_KEY"},
+		cli.StringFlag{Name: "keychain", Value: "", Usage: "sign with this key from the OS keychain instead of a seed", EnvVar: "TX_KEYCHAIN"},
+		cli.StringFlag{Name: "key-passphrase", Value: "", Usage: "the keystore passphrase, prompted for if not set", EnvVar: "TX_KEY_PASSPHRASE"},
+		cli.StringFlag{Name: "keystore", Value: "", Usage: "the keystore directory, defaults to tx/keystore in the user config directory", EnvVar: "TX_KEYSTORE"},
+		cli.StringFlag{Name: "account", Value: "", Usage: "the sending account, when the key is its regular key", EnvVar: "TX_ACCOUNT"},
+		cli.StringFlag{Name: "addressbook", Value: "", Usage: "the address book file for @name addresses, defaults to tx/addressbook.json in the user config directory", EnvVar: "TX_ADDRESSBOOK"},
+		cli.StringFlag{Name: "secret-hex", Value: "", Usage: "a raw private key in hex to use instead of a seed, ED prefixed or with --ed25519 for ed25519", EnvVar: "TX_SECRET_HEX"},
+		cli.StringFlag{Name: "mnemonic", Value: "", Usage: "a BIP39 phrase of English words to use instead of a seed", EnvVar: "TX_MNEMONIC"},
+		cli.StringFlag{Name: "passphrase", Value: "", Usage: "the optional BIP39 passphrase for --mnemonic", EnvVar: "TX_PASSPHRASE"},
+		cli.BoolFlag{Name: "ledger", Usage: "sign with the XRP app on a Ledger device, using the --hdpath account", EnvVar: "TX_LEDGER"},
+		cli.StringFlag{Name: "hdpath", Value: txlib.DefaultHDPath, Usage: "the BIP44 derivation path for --mnemonic or --ledger", EnvVar: "TX_HDPATH"},
+		cli.BoolFlag{Name: "ed25519,e", Usage: "seed is for an ed25519 account, implied by sEd seeds", EnvVar: "TX_ED25519"},
+		cli.IntFlag{Name: "fee,f", Value: 10, Usage: "the fee you want to pay", EnvVar: "TX_FEE"},
+		cli.StringFlag{Name: "fee-strategy", Value: "fixed", Usage: "fixed to pay --fee, or server to pay the server's open ledger fee unless --fee is given", EnvVar: "TX_FEE_STRATEGY"},
+		cli.IntFlag{Name: "sequence,q", Value: 0, Usage: "the sequence for the transaction", EnvVar: "TX_SEQUENCE"},
+		cli.IntFlag{Name: "lastledger,l", Value: 0, Usage: "highest ledger number that the transaction can appear in", EnvVar: "TX_LASTLEDGER"},
+		cli.StringFlag{Name: "account-txn-id", Value: "", Usage: "only apply after the account's transaction with this hash, or \"auto\" for its last one", EnvVar: "TX_ACCOUNT_TXN_ID"},
+		cli.BoolFlag{Name: "no-canonical", Usage: "do not set tfFullyCanonicalSig on secp256k1 transactions", EnvVar: "TX_NO_CANONICAL"},
+		cli.BoolFlag{Name: "unsigned", Usage: "output the transaction unsigned, for signing elsewhere. Needs --account if no key is given", EnvVar: "TX_UNSIGNED"},
+		cli.BoolFlag{Name: "submit,t", Usage: "submits the transaction via websocket", EnvVar: "TX_SUBMIT"},
+		cli.BoolFlag{Name: "binary,b", Usage: "raw output in binary", EnvVar: "TX_BINARY"},
+		cli.BoolFlag{Name: "json,j", Usage: "output only the resulting JSON", EnvVar: "TX_JSON"},
+		cli.StringFlag{Name: "output", Value: "", Usage: "full-json to print signed transactions as one JSON object with the hash, blob and, with --submit, the result once validated, table to align query results in columns, or yaml to print YAML wherever --json prints JSON", EnvVar: "TX_OUTPUT"},
+		cli.StringFlag{Name: "color", Value: "auto", Usage: "color transaction results: auto when stdout is a terminal and NO_COLOR is unset, always or never", EnvVar: "TX_COLOR"},
+		cli.StringFlag{Name: "out", Value: "", Usage: "write the signed transaction to this file, replacing it in one step, instead of printing it", EnvVar: "TX_OUT"},
+		cli.BoolFlag{Name: "quiet", Usage: "print only the transaction hash, or the signing hash if unsigned. A failed submission is printed to stderr and exits 1", EnvVar: "TX_QUIET"},
+		cli.StringFlag{Name: "server,u", Value: "wss://s-east.ripple.com:443", Usage: "websocket url of the rippled server, or a comma separated list to try in turn", EnvVar: "TX_SERVER"},
+		cli.StringFlag{Name: "proxy", Value: "", Usage: "connect through this http:// or socks5:// proxy, overriding HTTPS_PROXY and HTTP_PROXY", EnvVar: "TX_PROXY"},
+		cli.DurationFlag{Name: "connect-timeout", Value: 10 * time.Second, Usage: "give up connecting to a server after this long", EnvVar: "TX_CONNECT_TIMEOUT"},
+		cli.DurationFlag{Name: "request-timeout", Value: 30 * time.Second, Usage: "give up waiting for a server's reply after this long, 0 to wait forever", EnvVar: "TX_REQUEST_TIMEOUT"},
+		cli.StringFlag{Name: "ca-cert", Value: "", Usage: "trust the server's certificate if signed by a CA in this PEM file", EnvVar: "TX_CA_CERT"},
+		cli.StringFlag{Name: "client-cert", Value: "", Usage: "present this PEM certificate to servers requiring mutual TLS, with --client-key", EnvVar: "TX_CLIENT_CERT"},
+		cli.StringFlag{Name: "client-key", Value: "", Usage: "the PEM private key of --client-cert", EnvVar: "TX_CLIENT_KEY"},
+		cli.BoolFlag{Name: "insecure", Usage: "do not check the server's certificate", EnvVar: "TX_INSECURE"},
+		cli.BoolFlag{Name: "standalone", Usage: "use a local rippled in standalone mode at " + standaloneServer + " unless --server is given, closing the ledger after each submission", EnvVar: "TX_STANDALONE"},
+		cli.StringFlag{Name: "network", Value: "", Usage: "use the default server of the main, test or dev network", EnvVar: "TX_NETWORK"},
+		cli.IntFlag{Name: "network-id", Value: 0, Usage: "refuse to submit to a server on another network", EnvVar: "TX_NETWORK_ID"},
+		cli.StringFlag{Name: "definitions", Value: "", Usage: "sign JSON with the sign command using these server_definitions, a file or \"server\" to fetch them, for fields and types the built in encoder does not know", EnvVar: "TX_DEFINITIONS"},
+		cli.IntFlag{Name: "max-fee", Value: 0, Usage: "refuse to sign with a fee above this many drops", EnvVar: "TX_MAX_FEE"},
+		cli.StringFlag{Name: "config", Value: "", Usage: "read default flag values from this TOML file instead of tx/config.toml in the user config directory", EnvVar: "TX_CONFIG"},
+		cli.StringFlag{Name: "profile", Value: "", Usage: "use the settings of this profile in the config file", EnvVar: "TX_PROFILE"},
+		cli.BoolFlag{Name: "explorer", Usage: "print block explorer links for signed transactions and their accounts", EnvVar: "TX_EXPLORER"},
+		cli.StringFlag{Name: "explorer-tx-url", Value: "", Usage: "the explorer link for a transaction, with {hash} for its hash. Defaults to xrpl.org for the --network", EnvVar: "TX_EXPLORER_TX_URL"},
+		cli.StringFlag{Name: "explorer-account-url", Value: "", Usage: "the explorer link for an account, with {account} for its address", EnvVar: "TX_EXPLORER_ACCOUNT_URL"},
+		cli.BoolFlag{Name: "qr", Usage: "also show the transaction blob, or the address from xaddress and whoami, as a QR code in the terminal", EnvVar: "TX_QR"},
+		cli.StringFlag{Name: "qr-png", Value: "", Usage: "also write the QR code as a PNG to this file", EnvVar: "TX_QR_PNG"},
+		cli.StringFlag{Name: "metrics", Value: "", Usage: "serve Prometheus metrics at /metrics on this address, like :9100, while serve, watch, wait, ledgers or book --stream run", EnvVar: "TX_METRICS"},
+		cli.StringFlag{Name: "notify-url", Value: "", Usage: "POST JSON to this url when a transaction from watch, wait, serve or --submit with --output full-json is final", EnvVar: "TX_NOTIFY_URL"},
 	}
 	app.Before = common
 	app.Commands = []cli.Command{{
@@ -229,7 +686,8 @@ func main() {
 		Description: "seed, sequence, destination and amount are required",
 		Action:      payment,
 		Flags: []cli.Flag{
-			cli.StringFlag{Name: "dest,d", Value: "", Usage: "destination account"},
+			cli.StringFlag{Name: "dest,d", Value: "", Usage: "destination account, PayString or xrpl: payment request URI"},
+			cli.StringFlag{Name: "expect-dest", Value: "", Usage: "stop unless the destination resolves to this address, to pin a PayString"},
 			cli.StringFlag{Name: "amount,a", Value: "", Usage: "amount to send"},
 			cli.IntFlag{Name: "tag,t", Value: 0, Usage: "destination tag"},
 			cli.StringFlag{Name: "invoice,i", Value: "", Usage: "invoice id (will be passed through SHA512Half)"},
@@ -238,6 +696,10 @@ func main() {
 			cli.BoolFlag{Name: "nodirect,r", Usage: "do not look for direct path"},
 			cli.BoolFlag{Name: "partial,p", Usage: "permit partial payment"},
 			cli.BoolFlag{Name: "limit,l", Usage: "limit quality"},
+			cli.BoolFlag{Name: "all", Usage: "send all XRP above the reserve, less the fee"},
+			cli.BoolFlag{Name: "check", Usage: "check the destination can receive the payment, always done with --submit"},
+			cli.BoolFlag{Name: "force", Usage: "warn about failed destination checks instead of stopping"},
+			cli.BoolFlag{Name: "allow-self", Usage: "allow paying the signing account, for a cross-currency payment with --sendmax or --paths"},
 		},
 	}, {
 		Name:        "trust",
@@ -247,20 +709,526 @@ func main() {
 		Action:      trust,
 		Flags: []cli.Flag{
 			cli.StringFlag{Name: "amount,a", Value: "", Usage: "trust limit"},
-			cli.Float64Flag{Name: "quality-out,q", Value: 1.0, Usage: "> 1.0 to charge a fee"},
-			cli.Float64Flag{Name: "quality-in,Q", Value: 1.0, Usage: "< 1.0 to charge a fee"},
+			cli.StringFlag{Name: "quality-out,q", Value: "1.0", Usage: "> 1.0, 100% or 10000bp to charge a fee"},
+			cli.StringFlag{Name: "quality-in,Q", Value: "1.0", Usage: "< 1.0, 100% or 10000bp to charge a fee"},
 			cli.BoolFlag{Name: "auth,A", Usage: "SetAuth"},
 			cli.BoolFlag{Name: "noripple,n", Usage: "no rippling on this trustline"},
 			cli.BoolFlag{Name: "clear-noripple,N", Usage: "re-enable rippling on this trustline"},
 			cli.BoolFlag{Name: "freeze,f", Usage: "freeze this trustline"},
 			cli.BoolFlag{Name: "clear-freeze,F", Usage: "unfreeze this trustline"},
+			cli.BoolFlag{Name: "deep-freeze", Usage: "freeze this trustline so the holder can neither send nor receive, needs the DeepFreeze amendment"},
+			cli.BoolFlag{Name: "clear-deep-freeze", Usage: "lift a deep freeze, leaving the trustline frozen unless --clear-freeze is also given"},
+			cli.BoolFlag{Name: "verify-issuer", Usage: "warn if the issuer's domain does not list it in its xrp-ledger.toml"},
 		},
+	}, {
+		Name:        "accountset",
+		Usage:       "set or clear an account flag",
+		Description: "seed and a flag to set or clear are required. Flags are asf values or names, e.g. asfDefaultRipple, DefaultRipple or 8",
+		Action:      accountSet,
+		Flags: []cli.Flag{
+			cli.StringFlag{Name: "set", Value: "", Usage: "the flag to set"},
+			cli.StringFlag{Name: "clear", Value: "", Usage: "the flag to clear"},
+			cli.BoolFlag{Name: "force", Usage: "warn instead of stopping when asfAllowTrustLineClawback would be refused"},
+		},
+	}, {
+		Name:  "clawback",
+		Usage: "manage clawback of issued tokens",
+		Subcommands: []cli.Command{{
+			Name:        "enable",
+			Usage:       "let the account claw back the tokens it issues",
+			Description: "sets asfAllowTrustLineClawback, which needs an account with no trust lines and cannot be undone. Seed is required",
+			Action:      clawbackEnable,
+			Flags: []cli.Flag{
+				cli.BoolFlag{Name: "force", Usage: "warn instead of stopping if the account has trust lines"},
+			},
+		}},
+	}, {
+		Name:  "offer",
+		Usage: "create and cancel offers",
+		Subcommands: []cli.Command{{
+			Name:        "create",
+			Usage:       "offer to sell one amount for another",
+			Description: "seed, sequence, and the amounts the taker gets and pays are required",
+			Action:      offerCreate,
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "gets,g", Value: "", Usage: "the amount the taker gets, which the offer sells"},
+				cli.StringFlag{Name: "pays,p", Value: "", Usage: "the amount the taker pays, which the offer buys"},
+				cli.IntFlag{Name: "replace", Usage: "cancel the offer created by the transaction with this sequence"},
+				cli.BoolFlag{Name: "passive", Usage: "do not consume offers that exactly match this one"},
+				cli.BoolFlag{Name: "ioc", Usage: "immediate or cancel: take what can be filled now and never rest on the book"},
+				cli.BoolFlag{Name: "fok", Usage: "fill or kill: fill the whole offer now or not at all"},
+				cli.BoolFlag{Name: "sell", Usage: "sell all of taker gets even if that means getting more than taker pays"},
+				cli.StringFlag{Name: "expires", Value: "", Usage: "remove the offer after this RFC 3339 time, or a duration from now like +24h"},
+			},
+		}, {
+			Name:        "cancel",
+			Usage:       "cancel an offer",
+			Description: "pass the sequence of the transaction that created the offer",
+			Action:      offerCancel,
+		}, {
+			Name:        "grid",
+			Usage:       "sign a ladder of bids and asks around a mid price",
+			Description: "pass the base asset and the quote asset it is priced in, as XRP or currency/issuer. Sequences count up from --sequence or the account's next sequence",
+			Action:      offerGrid,
+			Flags: []cli.Flag{
+				cli.Float64Flag{Name: "mid", Usage: "the mid price in the quote asset, defaults to halfway between the best bid and ask"},
+				cli.StringFlag{Name: "step", Value: "", Usage: "the price difference between levels, or a percentage of the mid price like 0.5%"},
+				cli.IntFlag{Name: "count", Value: 5, Usage: "levels on each side"},
+				cli.StringFlag{Name: "size", Value: "", Usage: "the amount of the base asset at each level"},
+				cli.BoolFlag{Name: "cancel", Usage: "first cancel the account's offers in this market, such as a previous grid"},
+				cli.StringFlag{Name: "expires", Value: "", Usage: "remove the offers after this RFC 3339 time, or a duration from now like +24h"},
+			},
+		}},
 	}, {
 		Name:        "submit",
 		ShortName:   "s",
 		Usage:       "submit a transaction",
-		Description: "pass a transaction on stdin",
+		Description: "pass a transaction on stdin, or pass files of signed transactions as hex, JSON or binary to submit each of them",
 		Action:      submit,
+	}, {
+		Name:        "decode",
+		ShortName:   "d",
+		Usage:       "decode a transaction blob to JSON",
+		Description: "pass the hex blob as an argument or on stdin",
+		Action:      decode,
+		Flags: []cli.Flag{
+			cli.BoolFlag{Name: "verbose,v", Usage: "also name AccountSet SetFlag and ClearFlag values"},
+		},
+	}, {
+		Name:        "explain-flags",
+		Usage:       "name the bits of a Flags value",
+		Description: "pass a transaction or ledger entry type and the flags, e.g. Payment 0x20000. Use the type asf for AccountSet SetFlag values",
+		Action:      explainFlagsCmd,
+	}, {
+		Name:        "encode",
+		Usage:       "encode transaction JSON to binary without signing",
+		Description: "pass a JSON file as an argument or the JSON on stdin",
+		Action:      encode,
+	}, {
+		Name:        "sign",
+		Usage:       "sign a transaction of any type given as JSON",
+		Description: "pass a JSON file as an argument or the JSON on stdin. Sequence, Fee and LastLedgerSequence from the flags override the JSON",
+		Action:      signCmd,
+		Flags: []cli.Flag{
+			cli.BoolFlag{Name: "autofill", Usage: "fetch the Sequence, Fee and LastLedgerSequence from the server when not set"},
+			cli.StringSliceFlag{Name: "hook-param", Usage: "a name=value HookParameter to attach, text or hex with a 0x prefix, may be repeated. Needs --definitions for a network with hooks"},
+		},
+	}, {
+		Name:        "render",
+		Usage:       "fill in a transaction template and sign it",
+		Description: "pass the template file as an argument. Placeholders are written {{name}} and set with --set name=value",
+		Action:      render,
+		Flags: []cli.Flag{
+			cli.StringSliceFlag{Name: "set", Usage: "a name=value to substitute, may be repeated"},
+			cli.BoolFlag{Name: "autofill", Usage: "fetch the Sequence, Fee and LastLedgerSequence from the server when not set"},
+		},
+	}, {
+		Name:        "hash",
+		Usage:       "print the hash of a transaction blob",
+		Description: "prints the signing hash if the blob is unsigned. Pass the hex blob as an argument or on stdin",
+		Action:      hash,
+	}, {
+		Name:        "verify",
+		Usage:       "verify the signatures on a transaction blob",
+		Description: "pass the hex blob or JSON as an argument or on stdin. Exits non-zero if any signature is invalid",
+		Action:      verify,
+	}, {
+		Name:        "paths",
+		Usage:       "find payment paths",
+		Description: "destination and amount are required, source defaults to the seed's account",
+		Action:      paths,
+		Flags: []cli.Flag{
+			cli.StringFlag{Name: "dest,d", Value: "", Usage: "destination account"},
+			cli.StringFlag{Name: "amount,a", Value: "", Usage: "amount to be delivered"},
+			cli.StringFlag{Name: "from", Value: "", Usage: "source account"},
+			cli.StringFlag{Name: "currencies,c", Value: "", Usage: "comma separated source currencies"},
+			cli.BoolFlag{Name: "emit,e", Usage: "print only the paths, in the format the payment --paths flag expects"},
+		},
+	}, {
+		Name:        "obligations",
+		Usage:       "show currencies issued by a gateway",
+		Description: "pass the issuer as an argument, defaults to the seed's account",
+		Action:      obligations,
+		Flags: []cli.Flag{
+			cli.StringFlag{Name: "hot", Value: "", Usage: "comma separated hot wallets to exclude from obligations"},
+		},
+	}, {
+		Name:        "norippleaudit",
+		Usage:       "check trustlines for incorrect rippling settings",
+		Description: "pass the account as an argument, defaults to the seed's account",
+		Action:      norippleaudit,
+		Flags: []cli.Flag{
+			cli.StringFlag{Name: "role,r", Value: "", Usage: "gateway or user"},
+			cli.IntFlag{Name: "limit", Value: 300, Usage: "maximum number of problems to report"},
+			cli.BoolFlag{Name: "fix", Usage: "output the suggested transactions, signed if a seed is given"},
+		},
+	}, {
+		Name:        "changes",
+		Usage:       "show the balance changes made by a transaction",
+		Description: "pass the transaction hash as an argument",
+		Action:      changes,
+	}, {
+		Name:        "history",
+		Usage:       "list an account's validated transactions, newest first",
+		Description: "pass the account as an argument, defaults to the seed's account",
+		Action:      history,
+		Flags: []cli.Flag{
+			cli.BoolFlag{Name: "csv", Usage: "print CSV rows of date, type, counterparty, amount, fee, delivered_amount, result and hash"},
+			cli.IntFlag{Name: "limit", Value: 200, Usage: "transactions to list, 0 for all"},
+		},
+	}, {
+		Name:        "statement",
+		Usage:       "list every change to an account's balances with the running balances",
+		Description: "pass the account as an argument, defaults to the seed's account. The history is replayed from the account's first transaction, and any closing balance that differs from the validated ledger is warned about",
+		Action:      statement,
+		Flags: []cli.Flag{
+			cli.BoolFlag{Name: "csv", Usage: "print CSV rows of date, ledger_index, hash, type, currency, debit, credit and balance"},
+		},
+	}, {
+		Name:        "export",
+		Usage:       "archive the transactions touching accounts in a ledger range",
+		Description: "pass the accounts as arguments. Each account's transactions are written oldest first to account.ndjson, one JSON object per line with tx, meta and ledger_index",
+		Action:      export,
+		Flags: []cli.Flag{
+			cli.IntFlag{Name: "from", Value: -1, Usage: "the first ledger, -1 for the oldest the server has"},
+			cli.IntFlag{Name: "to", Value: -1, Usage: "the last ledger, -1 for the newest validated ledger"},
+			cli.StringFlag{Name: "dir", Value: ".", Usage: "the directory to write the files to"},
+		},
+	}, {
+		Name:        "wait",
+		Usage:       "wait for a transaction to be validated or expire",
+		Description: "pass the transaction hash as an argument. Exits 0 if it succeeded, 1 if it failed and 2 if its LastLedgerSequence, or --lastledger, passed",
+		Action:      wait,
+	}, {
+		Name:        "watch",
+		Usage:       "print validated transactions affecting accounts as they arrive",
+		Description: "pass the accounts as arguments. Prints the server's JSON with --json",
+		Action:      watch,
+	}, {
+		Name:        "ledgers",
+		Usage:       "print each ledger as it closes",
+		Description: "fees and reserves are in drops. Prints the server's JSON with --json",
+		Action:      ledgers,
+	}, {
+		Name:        "ledgerindex",
+		Usage:       "print the latest validated ledger index",
+		Description: "add --time for the ledger's close time too",
+		Action:      ledgerIndex,
+		Flags: []cli.Flag{
+			cli.BoolFlag{Name: "time", Usage: "also print the close time"},
+		},
+	}, {
+		Name:        "ping",
+		Usage:       "check the servers are reachable and in sync",
+		Description: "pings each --server and reports its latency, state and validated ledger. Exits 1 if any is unreachable, not in sync or its validated ledger is older than --max-age",
+		Action:      ping,
+		Flags: []cli.Flag{
+			cli.DurationFlag{Name: "max-age", Value: 30 * time.Second, Usage: "the oldest a healthy server's validated ledger can be"},
+		},
+	}, {
+		Name:        "validations",
+		Usage:       "show which validators signed each ledger",
+		Description: "follows the validations stream and summarizes each ledger shortly after it closes, with the validators grouped by the hash they validated",
+		Action:      validations,
+		Flags: []cli.Flag{
+			cli.StringFlag{Name: "unl", Value: "", Usage: "a file of the validator master keys to expect, one per line, to show who is missing and if they reach quorum"},
+			cli.BoolFlag{Name: "verbose,v", Usage: "list the validators for each hash"},
+		},
+	}, {
+		Name:  "manifest",
+		Usage: "work with validator manifests",
+		Subcommands: []cli.Command{{
+			Name:        "decode",
+			Usage:       "show a validator manifest's keys, sequence and domain and verify its signatures",
+			Description: "pass the base64 manifest as an argument or on stdin. Exits 1 if a signature is invalid",
+			Action:      manifestDecode,
+		}},
+	}, {
+		Name:        "fees",
+		Usage:       "show the open ledger fee and how full the ledger and queue are",
+		Description: "the ledger and queue are shown as transactions in them out of the expected or maximum number",
+		Action:      fees,
+		Flags: []cli.Flag{
+			cli.BoolFlag{Name: "stream", Usage: "keep printing them as each ledger closes"},
+		},
+	}, {
+		Name:        "amendments",
+		Usage:       "list the enabled amendments and those close to being enabled",
+		Description: "pending amendments are those with a majority of validators, which are enabled if they keep it for two weeks",
+		Action:      amendments,
+	}, {
+		Name:        "ledger-accept",
+		Usage:       "close the current ledger of a standalone rippled",
+		Description: "needs an admin connection, as to a rippled started with --standalone",
+		Action:      ledgerAcceptCmd,
+	}, {
+		Name:        "serve",
+		Usage:       "run an HTTP signing service for the key",
+		Description: "POST /sign and POST /submit take transaction JSON, or a {\"tx_blob\"} to submit. GET /tx/<hash> looks a transaction up",
+		Action:      serve,
+		Flags: []cli.Flag{
+			cli.StringFlag{Name: "listen", Value: "127.0.0.1:8080", Usage: "address to listen on"},
+			cli.StringFlag{Name: "grpc", Value: "", Usage: "also serve the gRPC Signer service of signerpb/signer.proto on this address"},
+			cli.StringFlag{Name: "token", Value: "", Usage: "require this bearer token on every request", EnvVar: "TX_SERVE_TOKEN"},
+		},
+	}, {
+		Name:        "book",
+		Usage:       "show an order book",
+		Description: "pass the asset sold and the asset wanted, as XRP or currency/issuer",
+		Action:      book,
+		Flags: []cli.Flag{
+			cli.BoolFlag{Name: "stream", Usage: "keep printing offers as they are created (+), changed (~) and removed (-)"},
+			cli.IntFlag{Name: "limit", Value: 20, Usage: "offers to fetch"},
+		},
+	}, {
+		Name:        "spread",
+		Usage:       "show the best bid and ask, spread and depth of a market",
+		Description: "pass the base asset and the quote asset it is priced in, as XRP or currency/issuer",
+		Action:      spread,
+		Flags: []cli.Flag{
+			cli.StringFlag{Name: "levels", Value: "0.5,1,2,5", Usage: "comma separated percentages from the mid price to total the depth within"},
+			cli.IntFlag{Name: "limit", Value: 200, Usage: "offers to fetch on each side"},
+		},
+	}, {
+		Name:        "verifyissuer",
+		Usage:       "check an issuer is listed in the xrp-ledger.toml of its domain",
+		Description: "pass the issuing account. Exits non-zero if it is not verified",
+		Action:      verifyIssuerCmd,
+	}, {
+		Name:        "info",
+		Usage:       "show an account's settings with its flags named",
+		Description: "pass the account as an argument, defaults to the seed's account",
+		Action:      info,
+	}, {
+		Name:        "reserve",
+		Usage:       "show an account's reserve and spendable XRP",
+		Description: "pass the account as an argument, defaults to the seed's account",
+		Action:      reserveCmd,
+	}, {
+		Name:        "sweep",
+		Usage:       "send all XRP above the reserve",
+		Description: "seed, sequence and destination are required",
+		Action:      sweep,
+		Flags: []cli.Flag{
+			cli.StringFlag{Name: "dest,d", Value: "", Usage: "destination account"},
+			cli.IntFlag{Name: "tag,t", Value: 0, Usage: "destination tag"},
+			cli.BoolFlag{Name: "check", Usage: "check the destination can receive the payment, always done with --submit"},
+		},
+	}, {
+		Name:        "payout",
+		Usage:       "sign a batch of payments from a CSV file",
+		Description: "rows are destination, amount, tag, memo and the keystore key or config profile to pay from, if not the seed's account. Sequences count up from --sequence or each account's next sequence",
+		Action:      payout,
+		Flags: []cli.Flag{
+			cli.StringFlag{Name: "out,o", Value: "", Usage: "write the hashes and blobs to this file instead of stdout"},
+		},
+	}, {
+		Name:  "nft",
+		Usage: "mint and query non-fungible tokens",
+		Subcommands: []cli.Command{{
+			Name:        "mint",
+			Usage:       "mint a token",
+			Description: "seed is required. The URI is stored as given, so there is no need to hex encode it",
+			Action:      nftMint,
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "taxon", Value: "0", Usage: "the taxon grouping the issuer's tokens, from 0 to 4294967295"},
+				cli.StringFlag{Name: "transfer-fee", Value: "", Usage: "the percentage of each resale paid to the issuer, up to 50%, e.g. 2.5%. Implies --transferable"},
+				cli.BoolFlag{Name: "transferable", Usage: "let holders sell or give the token to others, not only back to the issuer"},
+				cli.BoolFlag{Name: "burnable", Usage: "let the issuer burn the token"},
+				cli.BoolFlag{Name: "only-xrp", Usage: "only allow offers in XRP"},
+				cli.StringFlag{Name: "uri", Value: "", Usage: "the token's metadata link, such as https://example.com/1.json or ipfs://CID"},
+				cli.StringFlag{Name: "uri-file", Value: "", Usage: "use the ipfs:// URI of this file's CID, which must then be pinned on IPFS"},
+			},
+		}, {
+			Name:        "mintbatch",
+			Usage:       "sign a mint for every token of a manifest",
+			Description: "pass a JSON array of objects with uri, taxon, transfer_fee and key, or a CSV file of those columns. The key is a keystore key or config profile to mint with, if not the seed's. Sequences count up from --sequence or each account's next sequence",
+			Action:      nftMintBatch,
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "out,o", Value: "", Usage: "write the hashes and blobs to this file instead of stdout"},
+				cli.DurationFlag{Name: "interval", Value: 250 * time.Millisecond, Usage: "wait this long between submissions with --submit"},
+				cli.StringFlag{Name: "transfer-fee", Value: "", Usage: "the transfer fee of tokens whose row gives none, up to 50%. Implies --transferable"},
+				cli.BoolFlag{Name: "transferable", Usage: "let holders sell or give the tokens to others"},
+				cli.BoolFlag{Name: "burnable", Usage: "let the issuer burn the tokens"},
+				cli.BoolFlag{Name: "only-xrp", Usage: "only allow offers in XRP"},
+			},
+		}, {
+			Name:        "create-offer",
+			Usage:       "offer to sell or buy a token",
+			Description: "pass the token id as an argument. Seed and amount are required, with --sell or the --owner to buy from",
+			Action:      nftCreateOffer,
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "amount,a", Value: "", Usage: "the price asked or bid"},
+				cli.BoolFlag{Name: "sell", Usage: "sell a token the account holds"},
+				cli.StringFlag{Name: "owner", Value: "", Usage: "the holder of the token to buy"},
+				cli.StringFlag{Name: "destination", Value: "", Usage: "only let this account accept the offer, for a private sale or a broker"},
+				cli.StringFlag{Name: "expires", Value: "", Usage: "remove the offer after this RFC 3339 time, or a duration from now like +24h"},
+			},
+		}, {
+			Name:        "list",
+			Usage:       "list the tokens held by an account",
+			Description: "pass the account as an argument, defaults to the seed's account",
+			Action:      nftList,
+			Flags: []cli.Flag{
+				cli.IntFlag{Name: "limit", Value: 400, Usage: "tokens to fetch per request"},
+			},
+		}, {
+			Name:        "offers",
+			Usage:       "list the buy and sell offers for a token",
+			Description: "pass the token id as an argument",
+			Action:      nftOffers,
+		}},
+	}, {
+		Name:  "amm",
+		Usage: "query automated market makers",
+		Subcommands: []cli.Command{{
+			Name:        "info",
+			Usage:       "show an AMM's pool, fee and auction slot",
+			Description: "pass the two pool assets as arguments, e.g. XRP USD/rIssuer",
+			Action:      ammInfo,
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "account", Value: "", Usage: "look up the AMM by its account instead"},
+			},
+		}},
+	}, {
+		Name:        "keyconvert",
+		Usage:       "show a seed in each format with its secp256k1 and ed25519 keys",
+		Description: "pass a family seed, the seed's 16 bytes of entropy in hex, or its 12 RFC 1751 words as rippled shows them",
+		Action:      keyconvert,
+	}, {
+		Name:        "keygen",
+		Usage:       "generate a random seed",
+		Description: "use the global --ed25519 flag for an ed25519 seed",
+		Action:      keygen,
+	}, {
+		Name:        "whoami",
+		Aliases:     []string{"derive"},
+		Usage:       "show the account and public key of the seed",
+		Description: "seed is required",
+		Action:      whoami,
+		Flags: []cli.Flag{
+			cli.IntFlag{Name: "index,i", Value: 0, Usage: "also show this key sequence of a secp256k1 family"},
+		},
+	}, {
+		Name:        "wizard",
+		Usage:       "build, sign and submit a transaction by answering questions",
+		Description: "asks for the transaction type and its fields, then shows the transaction before signing it and again before submitting it",
+		Action:      wizard,
+	}, {
+		Name:  "addressbook",
+		Usage: "manage named destinations, used as @name wherever an address is expected",
+		Subcommands: []cli.Command{{
+			Name:        "add",
+			Usage:       "save an address under a name",
+			Description: "pass the name and the classic address or X-address as arguments",
+			Action:      addressBookAdd,
+			Flags: []cli.Flag{
+				cli.IntFlag{Name: "tag", Usage: "the destination tag to use when none is given"},
+				cli.StringFlag{Name: "notes", Value: "", Usage: "a note to show in the list"},
+				cli.BoolFlag{Name: "force", Usage: "replace an existing entry"},
+			},
+		}, {
+			Name:   "list",
+			Usage:  "list the saved addresses",
+			Action: addressBookList,
+		}, {
+			Name:        "remove",
+			Usage:       "remove a saved address",
+			Description: "pass the name as an argument",
+			Action:      addressBookRemove,
+		}},
+	}, {
+		Name:  "keystore",
+		Usage: "manage passphrase encrypted keys",
+		Subcommands: []cli.Command{{
+			Name:        "create",
+			Usage:       "encrypt a seed and save it",
+			Description: "pass the key name as an argument. The seed and passphrase are prompted for",
+			Action:      keystoreCreate,
+			Flags: []cli.Flag{
+				cli.BoolFlag{Name: "generate", Usage: "save a new random seed instead"},
+			},
+		}, {
+			Name:   "list",
+			Usage:  "list the saved keys and their accounts",
+			Action: keystoreList,
+		}},
+	}, {
+		Name:  "keychain",
+		Usage: "manage seeds in the macOS Keychain, GNOME Keyring or Windows Credential Manager",
+		Subcommands: []cli.Command{{
+			Name:        "set",
+			Usage:       "save a seed in the keychain",
+			Description: "pass the key name as an argument. The seed is prompted for",
+			Action:      keychainSet,
+		}, {
+			Name:        "delete",
+			Usage:       "remove a seed from the keychain",
+			Description: "pass the key name as an argument",
+			Action:      keychainDelete,
+		}},
+	}, {
+		Name:        "xaddress",
+		Usage:       "convert between classic addresses and X-addresses",
+		Description: "pass a classic address, with --tag to embed a tag, or an X-address to decode",
+		Action:      xaddress,
+		Flags: []cli.Flag{
+			cli.IntFlag{Name: "tag,t", Value: 0, Usage: "destination tag to embed"},
+			cli.BoolFlag{Name: "test", Usage: "encode for a test network"},
+		},
+	}, {
+		Name:        "request",
+		Usage:       "make a payment request URI",
+		Description: "prints an xrpl: URI for a wallet, or for payment --dest, with --amount in XRP or as value/currency/issuer",
+		Action:      paymentURI,
+		Flags: []cli.Flag{
+			cli.StringFlag{Name: "dest,d", Value: "", Usage: "account to be paid"},
+			cli.StringFlag{Name: "amount,a", Value: "", Usage: "amount requested, left to the payer if not given"},
+			cli.IntFlag{Name: "tag,t", Value: 0, Usage: "destination tag"},
+		},
+	}, {
+		Name:  "paychan",
+		Usage: "work with payment channels",
+		Subcommands: []cli.Command{{
+			Name:        "list",
+			Usage:       "list an account's open channels",
+			Description: "pass the account as an argument, defaults to the seed's account",
+			Action:      paychanList,
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "dest,d", Value: "", Usage: "only the channels to this destination"},
+				cli.IntFlag{Name: "limit", Value: 200, Usage: "channels to fetch per request"},
+			},
+		}, {
+			Name:        "verifyclaim",
+			Usage:       "verify a claim signature without the network",
+			Description: "channel, amount, public key and signature are required. Exits non-zero if the signature is invalid",
+			Action:      verifyClaim,
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "channel,c", Value: "", Usage: "the channel id in hex"},
+				cli.StringFlag{Name: "amount,a", Value: "", Usage: "the claimed amount in drops"},
+				cli.StringFlag{Name: "pubkey,p", Value: "", Usage: "the channel's public key, in hex or base58"},
+				cli.StringFlag{Name: "signature", Value: "", Usage: "the claim signature in hex"},
+			},
+		}},
+	}, {
+		Name:  "condition",
+		Usage: "make and check PREIMAGE-SHA-256 crypto-conditions for escrows",
+		Subcommands: []cli.Command{{
+			Name:        "generate",
+			Usage:       "print a condition and its fulfillment",
+			Description: "the preimage is 32 random bytes unless given. Keep the fulfillment secret until the escrow is finished",
+			Action:      conditionGenerate,
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "preimage", Value: "", Usage: "the preimage in hex"},
+			},
+		}, {
+			Name:        "verify",
+			Usage:       "check a fulfillment matches a condition",
+			Description: "pass the condition and fulfillment in hex as arguments. Exits non-zero if they do not match",
+			Action:      conditionVerify,
+		}},
 	}}
-	app.Run(os.Args)
+	loadPlugins()
+	app.Commands = builderCommands(app.Commands)
+	checkErr(app.Run(os.Args))
 }
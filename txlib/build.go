@@ -0,0 +1,116 @@
+package txlib
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/rubblelabs/ripple/data"
+)
+
+// NewPayment returns a payment of amount to destination, with no flags set.
+func NewPayment(destination data.Account, amount data.Amount) *data.Payment {
+	payment := &data.Payment{
+		Destination: destination,
+		Amount:      amount,
+	}
+	payment.TransactionType = data.PAYMENT
+	payment.Flags = new(data.TransactionFlag)
+	return payment
+}
+
+// NewTrustSet returns a trust line change to limit, with no flags set.
+func NewTrustSet(limit data.Amount) *data.TrustSet {
+	tx := &data.TrustSet{
+		LimitAmount: limit,
+	}
+	tx.TransactionType = data.TRUST_SET
+	tx.Flags = new(data.TransactionFlag)
+	return tx
+}
+
+// NewAccountSet returns an account settings change that sets and clears
+// nothing.
+func NewAccountSet() *data.AccountSet {
+	tx := &data.AccountSet{}
+	tx.TransactionType = data.ACCOUNT_SET
+	tx.Flags = new(data.TransactionFlag)
+	return tx
+}
+
+// NewOfferCreate returns an offer selling gets for pays, with no flags set.
+func NewOfferCreate(gets, pays data.Amount) *data.OfferCreate {
+	tx := &data.OfferCreate{
+		TakerGets: gets,
+		TakerPays: pays,
+	}
+	tx.TransactionType = data.OFFER_CREATE
+	tx.Flags = new(data.TransactionFlag)
+	return tx
+}
+
+// NewOfferCancel returns the cancellation of the offer made by the
+// transaction with sequence.
+func NewOfferCancel(sequence uint32) *data.OfferCancel {
+	tx := &data.OfferCancel{
+		OfferSequence: sequence,
+	}
+	tx.TransactionType = data.OFFER_CANCEL
+	tx.Flags = new(data.TransactionFlag)
+	return tx
+}
+
+// NewNFTokenMint returns the minting of a token in taxon, with no flags
+// set.
+func NewNFTokenMint(taxon uint32) *data.NFTokenMint {
+	tx := &data.NFTokenMint{
+		NFTokenTaxon: taxon,
+	}
+	tx.TransactionType = data.NFTOKEN_MINT
+	tx.Flags = new(data.TransactionFlag)
+	return tx
+}
+
+// NewNFTokenCreateOffer returns a buy offer of amount for a token, with no
+// flags set.
+func NewNFTokenCreateOffer(id data.Hash256, amount data.Amount) *data.NFTokenCreateOffer {
+	tx := &data.NFTokenCreateOffer{
+		NFTokenID: id,
+		Amount:    amount,
+	}
+	tx.TransactionType = data.NFTOKEN_CREATE_OFFER
+	tx.Flags = new(data.TransactionFlag)
+	return tx
+}
+
+// NewTextMemo returns a plain text memo.
+func NewTextMemo(text string) data.Memo {
+	return data.Memo{Memo: data.MemoItem{
+		MemoType:   data.VariableLength("memo"),
+		MemoData:   data.VariableLength(text),
+		MemoFormat: data.VariableLength("text/plain"),
+	}}
+}
+
+// ParseQuality reads a trust line quality as a ratio like 1.01, a
+// percentage like 101% or basis points like 10100bp, giving it in the
+// billionths TrustSet uses. A ratio of 1, the default, is 1000000000.
+func ParseQuality(s string) (uint32, error) {
+	number, scale := strings.TrimSpace(s), 1.0
+	switch {
+	case strings.HasSuffix(number, "%"):
+		number, scale = strings.TrimSuffix(number, "%"), 100
+	case strings.HasSuffix(number, "bp"):
+		number, scale = strings.TrimSuffix(number, "bp"), 10000
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	if err != nil {
+		return 0, fmt.Errorf("Bad quality %s", s)
+	}
+	billionths := math.Round(f / scale * 1e9)
+	if billionths < 0 || billionths > math.MaxUint32 {
+		return 0, fmt.Errorf("Quality %s is out of range, give a ratio like 1.01, a percentage like 101%% or basis points like 10100bp", s)
+	}
+	return uint32(billionths), nil
+}
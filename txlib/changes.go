@@ -0,0 +1,137 @@
+package txlib
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/rubblelabs/ripple/data"
+)
+
+type balanceKey struct {
+	Account  data.Account
+	Currency data.Currency
+	Issuer   data.Account
+}
+
+// BalanceChange is the net change to one account's balance of a currency.
+// For issued currencies the issuer is the counterparty of the trust line.
+type BalanceChange struct {
+	Account  data.Account  `json:"account"`
+	Currency data.Currency `json:"currency"`
+	Issuer   *data.Account `json:"issuer,omitempty"`
+	Change   *data.Value   `json:"change"`
+}
+
+func (b BalanceChange) String() string {
+	sign := ""
+	if !b.Change.IsNegative() {
+		sign = "+"
+	}
+	if b.Issuer == nil {
+		return fmt.Sprintf("%s%s XRP", sign, b.Change)
+	}
+	return fmt.Sprintf("%s%s %s/%s", sign, b.Change, b.Currency, b.Issuer)
+}
+
+func addChange(changes map[balanceKey]*data.Value, key balanceKey, change *data.Value) error {
+	if existing, ok := changes[key]; ok {
+		sum, err := existing.Add(*change)
+		if err != nil {
+			return err
+		}
+		change = sum
+	}
+	changes[key] = change
+	return nil
+}
+
+// NodeFields returns the fields of an affected node before and after the
+// transaction. Either may be nil if the node was created or deleted.
+func NodeFields(effect data.NodeEffect) (data.LedgerEntry, data.LedgerEntry) {
+	switch {
+	case effect.CreatedNode != nil:
+		return nil, effect.CreatedNode.NewFields
+	case effect.ModifiedNode != nil:
+		return effect.ModifiedNode.PreviousFields, effect.ModifiedNode.FinalFields
+	default:
+		return effect.DeletedNode.PreviousFields, effect.DeletedNode.FinalFields
+	}
+}
+
+// BalanceChanges computes the net balance changes of every account affected
+// by a transaction, including the fee paid by the sender.
+func BalanceChanges(txm *data.TransactionWithMetaData) ([]BalanceChange, error) {
+	changes := make(map[balanceKey]*data.Value)
+	for _, effect := range txm.MetaData.AffectedNodes {
+		previous, final := NodeFields(effect)
+		switch f := final.(type) {
+		case *data.AccountRoot:
+			if f.Balance == nil || f.Account == nil {
+				continue
+			}
+			change := f.Balance
+			if p, ok := previous.(*data.AccountRoot); ok && p != nil {
+				if p.Balance == nil {
+					continue
+				}
+				var err error
+				if change, err = f.Balance.Subtract(*p.Balance); err != nil {
+					return nil, err
+				}
+			} else if effect.CreatedNode == nil {
+				continue
+			}
+			if err := addChange(changes, balanceKey{Account: *f.Account}, change); err != nil {
+				return nil, err
+			}
+		case *data.RippleState:
+			if f.Balance == nil {
+				continue
+			}
+			change := f.Balance.Value
+			if p, ok := previous.(*data.RippleState); ok && p != nil {
+				if p.Balance == nil {
+					continue
+				}
+				diff, err := f.Balance.Subtract(p.Balance)
+				if err != nil {
+					return nil, err
+				}
+				change = diff.Value
+			} else if effect.CreatedNode == nil {
+				continue
+			}
+			// The balance is held from the low account's point of view.
+			low, high := f.LowLimit.Issuer, f.HighLimit.Issuer
+			if err := addChange(changes, balanceKey{low, f.Balance.Currency, high}, change); err != nil {
+				return nil, err
+			}
+			if err := addChange(changes, balanceKey{high, f.Balance.Currency, low}, change.Negate()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var result []BalanceChange
+	for key, change := range changes {
+		if change.IsZero() {
+			continue
+		}
+		b := BalanceChange{Account: key.Account, Currency: key.Currency, Change: change}
+		if !key.Issuer.IsZero() {
+			issuer := key.Issuer
+			b.Issuer = &issuer
+		}
+		result = append(result, b)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Account.Equals(result[j].Account) {
+			return result[i].Account.String() < result[j].Account.String()
+		}
+		if !result[i].Currency.Equals(result[j].Currency) {
+			return result[i].Currency.Less(result[j].Currency)
+		}
+		return result[i].Issuer != nil && result[j].Issuer != nil && result[i].Issuer.Less(*result[j].Issuer)
+	})
+	return result, nil
+}
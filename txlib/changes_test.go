@@ -0,0 +1,97 @@
+package txlib
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rubblelabs/ripple/data"
+)
+
+// Metadata in which rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf pays 1 XRP to
+// rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh and 20 XRP to fund
+// rPT1Sjq2YGrBMTttX4GZHjKu9dyfzbpAYe, with a fee of 12 drops, and
+// rPT1Sjq2YGrBMTttX4GZHjKu9dyfzbpAYe gains 10 USD on its trust line to
+// rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B. The last node changes no balance.
+const testMetaData = `{
+	"AffectedNodes": [
+		{"ModifiedNode": {
+			"LedgerEntryType": "AccountRoot",
+			"FinalFields": {"Account": "rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf", "Balance": "78999988", "Flags": 0, "OwnerCount": 0, "Sequence": 8},
+			"PreviousFields": {"Balance": "100000000", "Sequence": 7}
+		}},
+		{"ModifiedNode": {
+			"LedgerEntryType": "AccountRoot",
+			"FinalFields": {"Account": "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh", "Balance": "6000000", "Flags": 0, "OwnerCount": 0, "Sequence": 1},
+			"PreviousFields": {"Balance": "5000000"}
+		}},
+		{"CreatedNode": {
+			"LedgerEntryType": "AccountRoot",
+			"NewFields": {"Account": "rPT1Sjq2YGrBMTttX4GZHjKu9dyfzbpAYe", "Balance": "20000000", "Sequence": 1}
+		}},
+		{"ModifiedNode": {
+			"LedgerEntryType": "RippleState",
+			"FinalFields": {
+				"Balance": {"currency": "USD", "issuer": "rrrrrrrrrrrrrrrrrrrrBZbvji", "value": "-15"},
+				"Flags": 131072,
+				"HighLimit": {"currency": "USD", "issuer": "rPT1Sjq2YGrBMTttX4GZHjKu9dyfzbpAYe", "value": "100"},
+				"LowLimit": {"currency": "USD", "issuer": "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", "value": "0"}
+			},
+			"PreviousFields": {"Balance": {"currency": "USD", "issuer": "rrrrrrrrrrrrrrrrrrrrBZbvji", "value": "-5"}}
+		}},
+		{"ModifiedNode": {
+			"LedgerEntryType": "AccountRoot",
+			"FinalFields": {"Account": "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", "Balance": "50000000", "Flags": 0, "OwnerCount": 2, "Sequence": 3},
+			"PreviousFields": {"OwnerCount": 1}
+		}}
+	],
+	"TransactionIndex": 0,
+	"TransactionResult": "tesSUCCESS"
+}`
+
+func TestBalanceChanges(t *testing.T) {
+	txm := &data.TransactionWithMetaData{}
+	if err := json.Unmarshal([]byte(testMetaData), &txm.MetaData); err != nil {
+		t.Fatal(err)
+	}
+	changes, err := BalanceChanges(txm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		account string
+		change  string
+		native  bool
+		issuer  string
+	}{
+		{"rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf", "-21000012", true, ""},
+		{"rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh", "1000000", true, ""},
+		{"rPT1Sjq2YGrBMTttX4GZHjKu9dyfzbpAYe", "20000000", true, ""},
+		{"rPT1Sjq2YGrBMTttX4GZHjKu9dyfzbpAYe", "10", false, "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"},
+		{"rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", "-10", false, "rPT1Sjq2YGrBMTttX4GZHjKu9dyfzbpAYe"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("%d balance changes, want %d: %v", len(changes), len(want), changes)
+	}
+	for _, w := range want {
+		value, err := data.NewValue(w.change, w.native)
+		if err != nil {
+			t.Fatal(err)
+		}
+		found := false
+		for _, b := range changes {
+			if b.Account.String() != w.account || (b.Issuer == nil) != w.native {
+				continue
+			}
+			if b.Issuer != nil && (b.Issuer.String() != w.issuer || b.Currency.String() != "USD") {
+				continue
+			}
+			found = true
+			if b.Change.Less(*value) || value.Less(*b.Change) {
+				t.Errorf("%s changed by %s, want %s", w.account, b, value)
+			}
+		}
+		if !found {
+			t.Errorf("No change for %s of %s", w.account, w.change)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package txlib
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/rubblelabs/ripple/crypto"
+)
+
+// The prefix of the data signed for a payment channel claim, "CLM\0"
+var claimPrefix = []byte{'C', 'L', 'M', 0}
+
+// ParsePublicKey reads a public key in hex, or base58 as rippled's
+// account_channels gives it.
+func ParsePublicKey(s string) ([]byte, error) {
+	if b, err := hex.DecodeString(s); err == nil {
+		if len(b) != 33 {
+			return nil, fmt.Errorf("Public key is %d bytes, expected 33", len(b))
+		}
+		return b, nil
+	}
+	h, err := crypto.NewRippleHashCheck(s, crypto.RIPPLE_ACCOUNT_PUBLIC)
+	if err != nil {
+		return nil, err
+	}
+	return h.Payload(), nil
+}
+
+// ClaimMessage is the signed data for a claim: the prefix, the channel id
+// and the amount of XRP in drops.
+func ClaimMessage(channel []byte, drops uint64) []byte {
+	msg := append(append([]byte{}, claimPrefix...), channel...)
+	return binary.BigEndian.AppendUint64(msg, drops)
+}
+
+// VerifyClaim checks a claim signature offline against the channel's public
+// key, as channel_verify does.
+func VerifyClaim(channel []byte, drops uint64, pub, sig []byte) bool {
+	msg := ClaimMessage(channel, drops)
+	ok, err := crypto.Verify(pub, crypto.Sha512Half(msg), msg, sig)
+	return err == nil && ok
+}
@@ -0,0 +1,47 @@
+package txlib
+
+import (
+	"crypto/sha256"
+	"encoding/asn1"
+	"fmt"
+)
+
+// PREIMAGE-SHA-256 crypto-conditions, the type EscrowCreate and
+// EscrowFinish accept. Both are DER encoded as a choice tagged [0] of
+// context specific fields.
+
+func contextField(tag int, compound bool, b []byte) []byte {
+	// Marshalling a RawValue only copies the bytes, so it cannot fail.
+	der, _ := asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: tag, IsCompound: compound, Bytes: b})
+	return der
+}
+
+// EncodeFulfillment gives the fulfillment of a preimage condition, for
+// EscrowFinish.
+func EncodeFulfillment(preimage []byte) []byte {
+	return contextField(0, true, contextField(0, false, preimage))
+}
+
+// EncodeCondition gives the fingerprint, the SHA-256 of the preimage, and
+// the cost, which is the preimage length.
+func EncodeCondition(preimage []byte) []byte {
+	fingerprint := sha256.Sum256(preimage)
+	cost, _ := asn1.Marshal(len(preimage))
+	fields := append(contextField(0, false, fingerprint[:]), contextField(1, false, cost[2:])...)
+	return contextField(0, true, fields)
+}
+
+// DecodeFulfillment returns the preimage of a fulfillment.
+func DecodeFulfillment(der []byte) ([]byte, error) {
+	var outer, inner asn1.RawValue
+	if rest, err := asn1.Unmarshal(der, &outer); err != nil || len(rest) > 0 {
+		return nil, fmt.Errorf("Bad fulfillment encoding")
+	}
+	if outer.Class != asn1.ClassContextSpecific || outer.Tag != 0 {
+		return nil, fmt.Errorf("Only PREIMAGE-SHA-256 fulfillments are supported")
+	}
+	if rest, err := asn1.Unmarshal(outer.Bytes, &inner); err != nil || len(rest) > 0 || inner.Tag != 0 {
+		return nil, fmt.Errorf("Bad fulfillment encoding")
+	}
+	return inner.Bytes, nil
+}
@@ -0,0 +1,545 @@
+package txlib
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rubblelabs/ripple/crypto"
+	"github.com/rubblelabs/ripple/data"
+)
+
+// The prefixes of the data signed for a transaction, "STX\0", and of the
+// data hashed for its id, "TXN\0"
+var (
+	signingPrefix = []byte{'S', 'T', 'X', 0}
+	txIDPrefix    = []byte{'T', 'X', 'N', 0}
+)
+
+// FieldDefinition is how a field is serialized.
+type FieldDefinition struct {
+	Nth            int    `json:"nth"`
+	IsVLEncoded    bool   `json:"isVLEncoded"`
+	IsSerialized   bool   `json:"isSerialized"`
+	IsSigningField bool   `json:"isSigningField"`
+	Type           string `json:"type"`
+}
+
+// Definitions are the types and fields a server encodes transactions with,
+// as its server_definitions command gives them. They let transactions be
+// encoded for networks with fields the data package does not know, such as
+// Xahau or a network with a new amendment.
+type Definitions struct {
+	Types              map[string]int
+	Fields             map[string]FieldDefinition
+	TransactionTypes   map[string]int
+	LedgerEntryTypes   map[string]int
+	TransactionResults map[string]int
+}
+
+// ParseDefinitions reads the result of server_definitions, or the
+// definitions.json file of the reference libraries, which has the same form.
+func ParseDefinitions(b []byte) (*Definitions, error) {
+	var raw struct {
+		Types              map[string]int      `json:"TYPES"`
+		Fields             [][]json.RawMessage `json:"FIELDS"`
+		TransactionTypes   map[string]int      `json:"TRANSACTION_TYPES"`
+		LedgerEntryTypes   map[string]int      `json:"LEDGER_ENTRY_TYPES"`
+		TransactionResults map[string]int      `json:"TRANSACTION_RESULTS"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	if len(raw.Types) == 0 || len(raw.Fields) == 0 {
+		return nil, fmt.Errorf("No TYPES or FIELDS in the definitions")
+	}
+	d := &Definitions{
+		Types:              raw.Types,
+		Fields:             make(map[string]FieldDefinition),
+		TransactionTypes:   raw.TransactionTypes,
+		LedgerEntryTypes:   raw.LedgerEntryTypes,
+		TransactionResults: raw.TransactionResults,
+	}
+	for _, pair := range raw.Fields {
+		if len(pair) != 2 {
+			return nil, fmt.Errorf("Bad field definition: %s", pair)
+		}
+		var name string
+		var field FieldDefinition
+		if err := json.Unmarshal(pair[0], &name); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(pair[1], &field); err != nil {
+			return nil, err
+		}
+		d.Fields[name] = field
+	}
+	return d, nil
+}
+
+type encodedField struct {
+	name  string
+	typ   int
+	field FieldDefinition
+}
+
+// fieldID is the header of a field: its type code and nth, each in a
+// nibble when small enough or else in a byte of its own.
+func fieldID(typ, nth int) []byte {
+	switch {
+	case typ < 16 && nth < 16:
+		return []byte{byte(typ<<4 | nth)}
+	case typ < 16:
+		return []byte{byte(typ << 4), byte(nth)}
+	case nth < 16:
+		return []byte{byte(nth), byte(typ)}
+	default:
+		return []byte{0, byte(typ), byte(nth)}
+	}
+}
+
+// vlPrefix is the length prefix of variable length data.
+func vlPrefix(n int) ([]byte, error) {
+	switch {
+	case n <= 192:
+		return []byte{byte(n)}, nil
+	case n <= 12480:
+		n -= 193
+		return []byte{byte(193 + n>>8), byte(n)}, nil
+	case n <= 918744:
+		n -= 12481
+		return []byte{byte(241 + n>>16), byte(n >> 8), byte(n)}, nil
+	default:
+		return nil, fmt.Errorf("%d bytes is too long for a field", n)
+	}
+}
+
+// Encode serializes a transaction given as rippled's JSON, decoded with
+// json.Decoder.UseNumber so large integers keep their precision. With
+// signing set only the fields that are signed are included.
+func (d *Definitions) Encode(tx map[string]interface{}, signing bool) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := d.encodeObject(&buf, tx, signing); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (d *Definitions) encodeObject(buf *bytes.Buffer, obj map[string]interface{}, signing bool) error {
+	var fields []encodedField
+	for name := range obj {
+		field, ok := d.Fields[name]
+		if !ok {
+			// Fields like hash and date are added by the server.
+			if name[0] >= 'a' && name[0] <= 'z' {
+				continue
+			}
+			return fmt.Errorf("Unknown field %s", name)
+		}
+		if !field.IsSerialized || (signing && !field.IsSigningField) {
+			continue
+		}
+		typ, ok := d.Types[field.Type]
+		if !ok {
+			return fmt.Errorf("Field %s has unknown type %s", name, field.Type)
+		}
+		fields = append(fields, encodedField{name, typ, field})
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].typ != fields[j].typ {
+			return fields[i].typ < fields[j].typ
+		}
+		return fields[i].field.Nth < fields[j].field.Nth
+	})
+	for _, f := range fields {
+		buf.Write(fieldID(f.typ, f.field.Nth))
+		value, err := d.encodeValue(f.name, f.field, obj[f.name], signing)
+		if err != nil {
+			return fmt.Errorf("%s: %s", f.name, err)
+		}
+		if f.field.IsVLEncoded {
+			prefix, err := vlPrefix(len(value))
+			if err != nil {
+				return fmt.Errorf("%s: %s", f.name, err)
+			}
+			buf.Write(prefix)
+		}
+		buf.Write(value)
+	}
+	return nil
+}
+
+// endMarker returns the header of the field that ends an object or array.
+func (d *Definitions) endMarker(name string) ([]byte, error) {
+	field, ok := d.Fields[name]
+	if !ok {
+		return nil, fmt.Errorf("No %s in the definitions", name)
+	}
+	return fieldID(d.Types[field.Type], field.Nth), nil
+}
+
+func (d *Definitions) encodeValue(name string, field FieldDefinition, v interface{}, signing bool) ([]byte, error) {
+	switch field.Type {
+	case "UInt8", "UInt16", "UInt32":
+		n, err := d.uintValue(name, v)
+		if err != nil {
+			return nil, err
+		}
+		size := map[string]int{"UInt8": 1, "UInt16": 2, "UInt32": 4}[field.Type]
+		if n >= 1<<(8*uint(size)) {
+			return nil, fmt.Errorf("%d does not fit a %s", n, field.Type)
+		}
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, n)
+		return b[8-size:], nil
+	case "UInt64":
+		// Given in hex, as JSON numbers cannot hold every value.
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a hex string")
+		}
+		n, err := strconv.ParseUint(s, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		return binary.BigEndian.AppendUint64(nil, n), nil
+	case "Hash128", "Hash160", "Hash192", "Hash256":
+		size := map[string]int{"Hash128": 16, "Hash160": 20, "Hash192": 24, "Hash256": 32}[field.Type]
+		b, err := hexValue(v)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != size {
+			return nil, fmt.Errorf("expected %d bytes, got %d", size, len(b))
+		}
+		return b, nil
+	case "Blob":
+		return hexValue(v)
+	case "Vector256":
+		items, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected an array of hashes")
+		}
+		var b []byte
+		for _, item := range items {
+			h, err := hexValue(item)
+			if err != nil {
+				return nil, err
+			}
+			if len(h) != 32 {
+				return nil, fmt.Errorf("expected 32 byte hashes, got %d bytes", len(h))
+			}
+			b = append(b, h...)
+		}
+		return b, nil
+	case "AccountID":
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected an address")
+		}
+		account, err := data.NewAccountFromAddress(s)
+		if err != nil {
+			return nil, err
+		}
+		return account.Bytes(), nil
+	case "Amount":
+		return encodeAmount(v)
+	case "Currency":
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a currency code")
+		}
+		return encodeCurrency(s)
+	case "Issue":
+		return encodeIssue(v)
+	case "PathSet":
+		return encodePathSet(v)
+	case "STObject":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected an object")
+		}
+		var buf bytes.Buffer
+		if err := d.encodeObject(&buf, obj, signing); err != nil {
+			return nil, err
+		}
+		end, err := d.endMarker("ObjectEndMarker")
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(end)
+		return buf.Bytes(), nil
+	case "STArray":
+		items, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected an array")
+		}
+		var buf bytes.Buffer
+		for _, item := range items {
+			// Each item is an object wrapped in its field name, like
+			// {"Memo": {...}}.
+			wrapper, ok := item.(map[string]interface{})
+			if !ok || len(wrapper) != 1 {
+				return nil, fmt.Errorf("expected objects with a single field")
+			}
+			if err := d.encodeObject(&buf, wrapper, signing); err != nil {
+				return nil, err
+			}
+		}
+		end, err := d.endMarker("ArrayEndMarker")
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(end)
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("type %s is not supported", field.Type)
+	}
+}
+
+// uintValue reads an integer field, which may be given by name for the
+// fields that name a transaction type, ledger entry type or result.
+func (d *Definitions) uintValue(name string, v interface{}) (uint64, error) {
+	names := map[string]map[string]int{
+		"TransactionType":   d.TransactionTypes,
+		"LedgerEntryType":   d.LedgerEntryTypes,
+		"TransactionResult": d.TransactionResults,
+	}[name]
+	switch n := v.(type) {
+	case json.Number:
+		return strconv.ParseUint(n.String(), 10, 64)
+	case float64:
+		if n < 0 || n != float64(uint64(n)) {
+			return 0, fmt.Errorf("%v is not an unsigned integer", n)
+		}
+		return uint64(n), nil
+	case string:
+		if code, ok := names[n]; ok {
+			return uint64(code), nil
+		}
+		if names != nil {
+			return 0, fmt.Errorf("unknown %s %s", name, n)
+		}
+		return strconv.ParseUint(n, 10, 64)
+	default:
+		return 0, fmt.Errorf("expected a number")
+	}
+}
+
+func hexValue(v interface{}) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected a hex string")
+	}
+	return hex.DecodeString(s)
+}
+
+// encodeCurrency gives a three letter code its standard form. Other codes
+// are 40 hex digits.
+func encodeCurrency(s string) ([]byte, error) {
+	b := make([]byte, 20)
+	switch {
+	case s == "XRP":
+		return b, nil
+	case len(s) == 3:
+		copy(b[12:], s)
+		return b, nil
+	case len(s) == 40:
+		return hex.DecodeString(s)
+	default:
+		return nil, fmt.Errorf("bad currency %s", s)
+	}
+}
+
+func encodeIssue(v interface{}) ([]byte, error) {
+	issue, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected an object with currency and issuer")
+	}
+	currency, _ := issue["currency"].(string)
+	b, err := encodeCurrency(currency)
+	if err != nil || currency == "XRP" {
+		return b, err
+	}
+	issuer, _ := issue["issuer"].(string)
+	account, err := data.NewAccountFromAddress(issuer)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, account.Bytes()...), nil
+}
+
+// encodePathSet encodes an array of paths, each an array of steps with an
+// account, or a currency and issuer.
+func encodePathSet(v interface{}) ([]byte, error) {
+	paths, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected an array of paths")
+	}
+	var b []byte
+	for i, p := range paths {
+		steps, ok := p.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected a path as an array of steps")
+		}
+		if i > 0 {
+			b = append(b, 0xFF)
+		}
+		for _, s := range steps {
+			step, ok := s.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("expected a path step object")
+			}
+			var typ byte
+			var fields []byte
+			if account, ok := step["account"].(string); ok {
+				a, err := data.NewAccountFromAddress(account)
+				if err != nil {
+					return nil, err
+				}
+				typ |= 0x01
+				fields = append(fields, a.Bytes()...)
+			}
+			if currency, ok := step["currency"].(string); ok {
+				c, err := encodeCurrency(currency)
+				if err != nil {
+					return nil, err
+				}
+				typ |= 0x10
+				fields = append(fields, c...)
+			}
+			if issuer, ok := step["issuer"].(string); ok {
+				a, err := data.NewAccountFromAddress(issuer)
+				if err != nil {
+					return nil, err
+				}
+				typ |= 0x20
+				fields = append(fields, a.Bytes()...)
+			}
+			b = append(append(b, typ), fields...)
+		}
+	}
+	return append(b, 0x00), nil
+}
+
+// encodeAmount encodes XRP given as a string of drops, or an issued amount
+// given as an object of value, currency and issuer.
+func encodeAmount(v interface{}) ([]byte, error) {
+	if s, ok := v.(string); ok {
+		drops, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad XRP amount %s", s)
+		}
+		n := uint64(drops)
+		if drops < 0 {
+			n = uint64(-drops)
+		} else {
+			n |= 0x4000000000000000
+		}
+		return binary.BigEndian.AppendUint64(nil, n), nil
+	}
+	amount, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected drops or an object with value, currency and issuer")
+	}
+	value, _ := amount["value"].(string)
+	n, err := encodeIssuedValue(value)
+	if err != nil {
+		return nil, err
+	}
+	issue, err := encodeIssue(amount)
+	if err != nil {
+		return nil, err
+	}
+	if len(issue) != 40 {
+		return nil, fmt.Errorf("an issued amount needs a currency other than XRP")
+	}
+	return append(binary.BigEndian.AppendUint64(nil, n), issue...), nil
+}
+
+// encodeIssuedValue packs a decimal value into the 64 bits of an issued
+// amount: a flag that it is not XRP, its sign, an exponent and a 54 bit
+// mantissa of 16 digits.
+func encodeIssuedValue(s string) (uint64, error) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, fmt.Errorf("bad value %s", s)
+	}
+	const notXRP uint64 = 0x8000000000000000
+	if r.Sign() == 0 {
+		return notXRP, nil
+	}
+	negative := r.Sign() < 0
+	r.Abs(r)
+	min, max := big.NewRat(1e15, 1), big.NewRat(1e16, 1)
+	ten := big.NewRat(10, 1)
+	exponent := 0
+	for r.Cmp(min) < 0 {
+		r.Mul(r, ten)
+		exponent--
+	}
+	for r.Cmp(max) >= 0 {
+		r.Quo(r, ten)
+		exponent++
+	}
+	if !r.IsInt() {
+		return 0, fmt.Errorf("value %s has more than 16 significant digits", s)
+	}
+	if exponent < -96 || exponent > 80 {
+		return 0, fmt.Errorf("value %s is out of range", s)
+	}
+	n := notXRP | uint64(exponent+97)<<54 | r.Num().Uint64()
+	if !negative {
+		n |= 0x4000000000000000
+	}
+	return n, nil
+}
+
+// SignJSON signs a transaction given as JSON using the definitions, setting
+// its SigningPubKey and TxnSignature. It returns the transaction id and the
+// signed blob. A RemoteSigner is asked for the signature.
+func (d *Definitions) SignJSON(tx map[string]interface{}, key crypto.Key, seq *uint32) (data.Hash256, []byte, error) {
+	var id data.Hash256
+	tx["SigningPubKey"] = strings.ToUpper(hex.EncodeToString(key.Public(seq)))
+	delete(tx, "TxnSignature")
+	msg, err := d.Encode(tx, true)
+	if err != nil {
+		return id, nil, err
+	}
+	msg = append(append([]byte{}, signingPrefix...), msg...)
+	hash := crypto.Sha512Half(msg)
+	var sig []byte
+	if signer, ok := key.(RemoteSigner); ok {
+		sig, err = signer.Sign(hash, msg)
+	} else {
+		sig, err = crypto.Sign(key.Private(seq), hash, msg)
+	}
+	if err != nil {
+		return id, nil, err
+	}
+	tx["TxnSignature"] = strings.ToUpper(hex.EncodeToString(sig))
+	blob, err := d.Encode(tx, false)
+	if err != nil {
+		return id, nil, err
+	}
+	copy(id[:], crypto.Sha512Half(append(append([]byte{}, txIDPrefix...), blob...)))
+	return id, blob, nil
+}
+
+// SigningHash returns the hash an unsigned transaction given as JSON is
+// signed over.
+func (d *Definitions) SigningHash(tx map[string]interface{}) (data.Hash256, error) {
+	var hash data.Hash256
+	msg, err := d.Encode(tx, true)
+	if err != nil {
+		return hash, err
+	}
+	copy(hash[:], crypto.Sha512Half(append(append([]byte{}, signingPrefix...), msg...)))
+	return hash, nil
+}
@@ -0,0 +1,192 @@
+package txlib
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rubblelabs/ripple/data"
+)
+
+// testDefinitions are the fields of server_definitions the vectors use,
+// with Xahau's HookParameters.
+const testDefinitions = `{
+	"TYPES": {"UInt16": 1, "UInt32": 2, "Amount": 6, "Blob": 7, "AccountID": 8, "STObject": 14, "STArray": 15},
+	"TRANSACTION_TYPES": {"Payment": 0},
+	"FIELDS": [
+		["TransactionType", {"nth": 2, "isVLEncoded": false, "isSerialized": true, "isSigningField": true, "type": "UInt16"}],
+		["NetworkID", {"nth": 1, "isVLEncoded": false, "isSerialized": true, "isSigningField": true, "type": "UInt32"}],
+		["Flags", {"nth": 2, "isVLEncoded": false, "isSerialized": true, "isSigningField": true, "type": "UInt32"}],
+		["Sequence", {"nth": 4, "isVLEncoded": false, "isSerialized": true, "isSigningField": true, "type": "UInt32"}],
+		["LastLedgerSequence", {"nth": 27, "isVLEncoded": false, "isSerialized": true, "isSigningField": true, "type": "UInt32"}],
+		["Amount", {"nth": 1, "isVLEncoded": false, "isSerialized": true, "isSigningField": true, "type": "Amount"}],
+		["Fee", {"nth": 8, "isVLEncoded": false, "isSerialized": true, "isSigningField": true, "type": "Amount"}],
+		["SigningPubKey", {"nth": 3, "isVLEncoded": true, "isSerialized": true, "isSigningField": true, "type": "Blob"}],
+		["TxnSignature", {"nth": 4, "isVLEncoded": true, "isSerialized": true, "isSigningField": false, "type": "Blob"}],
+		["HookParameterName", {"nth": 24, "isVLEncoded": true, "isSerialized": true, "isSigningField": true, "type": "Blob"}],
+		["HookParameterValue", {"nth": 25, "isVLEncoded": true, "isSerialized": true, "isSigningField": true, "type": "Blob"}],
+		["Account", {"nth": 1, "isVLEncoded": true, "isSerialized": true, "isSigningField": true, "type": "AccountID"}],
+		["Destination", {"nth": 3, "isVLEncoded": true, "isSerialized": true, "isSigningField": true, "type": "AccountID"}],
+		["ObjectEndMarker", {"nth": 1, "isVLEncoded": false, "isSerialized": true, "isSigningField": true, "type": "STObject"}],
+		["HookParameter", {"nth": 23, "isVLEncoded": false, "isSerialized": true, "isSigningField": true, "type": "STObject"}],
+		["ArrayEndMarker", {"nth": 1, "isVLEncoded": false, "isSerialized": true, "isSigningField": true, "type": "STArray"}],
+		["HookParameters", {"nth": 19, "isVLEncoded": false, "isSerialized": true, "isSigningField": true, "type": "STArray"}]
+	]
+}`
+
+// The Payment of rippled's submit example, its blob and its hash.
+const (
+	testPayment = `{
+		"TransactionType": "Payment",
+		"Account": "rf1BiGeXwwQoi8Z2ueFYTEXSwuJYfV2Jpn",
+		"Destination": "ra5nK24KXen9AHvsdFTKHSANinZseWnPcX",
+		"Amount": {"currency": "USD", "issuer": "rf1BiGeXwwQoi8Z2ueFYTEXSwuJYfV2Jpn", "value": "1"},
+		"Fee": "10",
+		"Flags": 2147483648,
+		"Sequence": 3,
+		"SigningPubKey": "03AB40A0490F9B7ED8DF29D246BF2D6269820A0EE7742ACDD457BEA7C7D0931EDB",
+		"TxnSignature": "3045022100D184EB4AE5956FF600E7536EE459345C7BBCF097A84CC61A93B9AF7197EDB98702201CEA8009B7BEEBAA2AACC0359B41C427C1C5B550A4CA4B80CF2174AF2D6D5DCE",
+		"hash": "82230B9D489370504B39BC2CE46216176CAC9E752E5C1774A8CBEC9FBB819208"
+	}`
+	testPaymentBlob    = "1200002280000000240000000361D4838D7EA4C6800000000000000000000000000055534400000000004B4E9C06F24296074F7BC48F92A97916C6DC5EA968400000000000000A732103AB40A0490F9B7ED8DF29D246BF2D6269820A0EE7742ACDD457BEA7C7D0931EDB74473045022100D184EB4AE5956FF600E7536EE459345C7BBCF097A84CC61A93B9AF7197EDB98702201CEA8009B7BEEBAA2AACC0359B41C427C1C5B550A4CA4B80CF2174AF2D6D5DCE81144B4E9C06F24296074F7BC48F92A97916C6DC5EA983143E9D4A2B8AA0780F682D136F7A56D6724EF53754"
+	testPaymentSigning = "1200002280000000240000000361D4838D7EA4C6800000000000000000000000000055534400000000004B4E9C06F24296074F7BC48F92A97916C6DC5EA968400000000000000A732103AB40A0490F9B7ED8DF29D246BF2D6269820A0EE7742ACDD457BEA7C7D0931EDB81144B4E9C06F24296074F7BC48F92A97916C6DC5EA983143E9D4A2B8AA0780F682D136F7A56D6724EF53754"
+)
+
+// testHookPayment is a Xahau Payment with a NetworkID and a HookParameter.
+const (
+	testHookPayment = `{
+		"TransactionType": "Payment",
+		"NetworkID": 21337,
+		"Account": "rf1BiGeXwwQoi8Z2ueFYTEXSwuJYfV2Jpn",
+		"Destination": "ra5nK24KXen9AHvsdFTKHSANinZseWnPcX",
+		"Amount": "1000000",
+		"Fee": "12",
+		"Flags": 0,
+		"Sequence": 1,
+		"LastLedgerSequence": 100,
+		"SigningPubKey": "03AB40A0490F9B7ED8DF29D246BF2D6269820A0EE7742ACDD457BEA7C7D0931EDB",
+		"HookParameters": [
+			{"HookParameter": {"HookParameterName": "6E616D65", "HookParameterValue": "76616C7565"}}
+		]
+	}`
+	testHookPaymentBlob = "120000" + // TransactionType
+		"2100005359" + // NetworkID
+		"2200000000" + // Flags
+		"2400000001" + // Sequence
+		"201B00000064" + // LastLedgerSequence
+		"6140000000000F4240" + // Amount
+		"68400000000000000C" + // Fee
+		"732103AB40A0490F9B7ED8DF29D246BF2D6269820A0EE7742ACDD457BEA7C7D0931EDB" + // SigningPubKey
+		"81144B4E9C06F24296074F7BC48F92A97916C6DC5EA9" + // Account
+		"83143E9D4A2B8AA0780F682D136F7A56D6724EF53754" + // Destination
+		"F013" + // HookParameters
+		"E017" + "7018046E616D65" + "70190576616C7565" + "E1" + // HookParameter
+		"F1"
+)
+
+func testTxMap(t *testing.T, s string) map[string]interface{} {
+	d := json.NewDecoder(strings.NewReader(s))
+	d.UseNumber()
+	var tx map[string]interface{}
+	if err := d.Decode(&tx); err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestDefinitionsEncode(t *testing.T) {
+	defs, err := ParseDefinitions([]byte(testDefinitions))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name    string
+		tx      string
+		signing bool
+		want    string
+	}{
+		{"payment", testPayment, false, testPaymentBlob},
+		{"payment signing", testPayment, true, testPaymentSigning},
+		{"hook payment", testHookPayment, false, testHookPaymentBlob},
+		{"hook payment signing", testHookPayment, true, testHookPaymentBlob},
+	} {
+		b, err := defs.Encode(testTxMap(t, test.tx), test.signing)
+		if err != nil {
+			t.Errorf("%s: Encode() error %s", test.name, err)
+			continue
+		}
+		if got := strings.ToUpper(hex.EncodeToString(b)); got != test.want {
+			t.Errorf("%s: Encode() = %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestDefinitionsEncodeErrors(t *testing.T) {
+	defs, err := ParseDefinitions([]byte(testDefinitions))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		field string
+		value interface{}
+	}{
+		{"Memos", []interface{}{}},
+		{"TransactionType", "NoSuchType"},
+		{"Sequence", json.Number("4294967296")},
+		{"Amount", map[string]interface{}{"currency": "USD", "issuer": "rf1BiGeXwwQoi8Z2ueFYTEXSwuJYfV2Jpn", "value": "1.2345678901234567"}},
+		{"Amount", map[string]interface{}{"currency": "XRP", "value": "1"}},
+		{"Account", "rNotAnAddress"},
+		{"HookParameters", []interface{}{map[string]interface{}{}}},
+	} {
+		tx := testTxMap(t, testHookPayment)
+		tx[test.field] = test.value
+		if b, err := defs.Encode(tx, false); err == nil {
+			t.Errorf("Encode() with %s %v = %X, want an error", test.field, test.value, b)
+		}
+	}
+}
+
+// TestDefinitionsMatchData encodes a transaction the data package reads and
+// checks the two agree on its blob, hash and signing hash.
+func TestDefinitionsMatchData(t *testing.T) {
+	defs, err := ParseDefinitions([]byte(testDefinitions))
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob, err := hex.DecodeString(testPaymentBlob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := data.ReadTransaction(bytes.NewReader(blob))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, raw, err := data.Raw(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingHash, _, err := data.SigningHash(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txMap := testTxMap(t, testPayment)
+	b, err := defs.Encode(txMap, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, raw) {
+		t.Errorf("Encode() = %X, data.Raw() = %X", b, raw)
+	}
+	if hash.String() != txMap["hash"] {
+		t.Errorf("data.Raw() hash = %s, want %s", hash, txMap["hash"])
+	}
+	got, err := defs.SigningHash(txMap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != signingHash {
+		t.Errorf("SigningHash() = %s, data.SigningHash() = %s", got, signingHash)
+	}
+}
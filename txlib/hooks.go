@@ -0,0 +1,118 @@
+package txlib
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// The limits Xahau puts on the HookParameters of a transaction
+const (
+	MaxHookParameters     = 16
+	MaxHookParameterName  = 32
+	MaxHookParameterValue = 256
+)
+
+const hookParameterHexPrefix = "0x"
+
+// hookParameterBytes reads a parameter name or value, as text or as hex
+// with a 0x prefix.
+func hookParameterBytes(s string) ([]byte, error) {
+	if strings.HasPrefix(s, hookParameterHexPrefix) {
+		return hex.DecodeString(s[len(hookParameterHexPrefix):])
+	}
+	return []byte(s), nil
+}
+
+// HookParameter reads a name=value pair, each text or hex with a 0x prefix,
+// and returns it as the JSON of a HookParameter entry.
+func HookParameter(s string) (map[string]interface{}, error) {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return nil, fmt.Errorf("Bad hook parameter %s, expected name=value", s)
+	}
+	name, err := hookParameterBytes(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("Bad hook parameter name %s: %s", parts[0], err)
+	}
+	value, err := hookParameterBytes(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("Bad hook parameter value %s: %s", parts[1], err)
+	}
+	if len(name) == 0 || len(name) > MaxHookParameterName {
+		return nil, fmt.Errorf("Hook parameter name %s is %d bytes, it must be from 1 to %d", parts[0], len(name), MaxHookParameterName)
+	}
+	if len(value) > MaxHookParameterValue {
+		return nil, fmt.Errorf("Hook parameter value for %s is %d bytes, the most is %d", parts[0], len(value), MaxHookParameterValue)
+	}
+	return map[string]interface{}{
+		"HookParameter": map[string]interface{}{
+			"HookParameterName":  strings.ToUpper(hex.EncodeToString(name)),
+			"HookParameterValue": strings.ToUpper(hex.EncodeToString(value)),
+		},
+	}, nil
+}
+
+// AddHookParameters appends the name=value parameters to the
+// HookParameters of a transaction given as JSON. Names must be unique.
+func (d *Definitions) AddHookParameters(tx map[string]interface{}, params []string) error {
+	if len(params) == 0 {
+		return nil
+	}
+	if !d.HasHooks() {
+		return fmt.Errorf("The network does not have hooks, its definitions have no HookParameters")
+	}
+	existing, _ := tx["HookParameters"].([]interface{})
+	names := make(map[string]bool)
+	for _, entry := range existing {
+		if name, ok := hookParameterName(entry); ok {
+			names[name] = true
+		}
+	}
+	for _, s := range params {
+		param, err := HookParameter(s)
+		if err != nil {
+			return err
+		}
+		name, _ := hookParameterName(param)
+		if names[name] {
+			return fmt.Errorf("Hook parameter %s is given more than once", strings.SplitN(s, "=", 2)[0])
+		}
+		names[name] = true
+		existing = append(existing, param)
+	}
+	if len(existing) > MaxHookParameters {
+		return fmt.Errorf("%d hook parameters given, a transaction can have at most %d", len(existing), MaxHookParameters)
+	}
+	tx["HookParameters"] = existing
+	return nil
+}
+
+func hookParameterName(entry interface{}) (string, bool) {
+	obj, ok := entry.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	param, ok := obj["HookParameter"].(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	name, ok := param["HookParameterName"].(string)
+	return strings.ToUpper(name), ok
+}
+
+// HasHooks reports whether the network the definitions are for has hooks,
+// so any transaction may run them and pay for it in its fee.
+func (d *Definitions) HasHooks() bool {
+	_, ok := d.Fields["HookParameters"]
+	return ok
+}
+
+// CheckEmitted refuses a transaction with EmitDetails, which only a hook can
+// set on the transactions it emits. Submitted, it fails with temMALFORMED.
+func CheckEmitted(tx map[string]interface{}) error {
+	if _, ok := tx["EmitDetails"]; ok {
+		return fmt.Errorf("EmitDetails is only set by hooks on the transactions they emit, remove it to sign")
+	}
+	return nil
+}
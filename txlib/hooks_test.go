@@ -0,0 +1,80 @@
+package txlib
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestHookParameter(t *testing.T) {
+	for _, test := range []struct {
+		in          string
+		name, value string
+		ok          bool
+	}{
+		{"amount=10", "616D6F756E74", "3130", true},
+		{"0xCAFE=0x00ff", "CAFE", "00FF", true},
+		{"key=", "6B6579", "", true},
+		{"a=b=c", "61", "623D63", true},
+		{strings.Repeat("n", MaxHookParameterName) + "=v", strings.Repeat("6E", MaxHookParameterName), "76", true},
+		{"n=" + strings.Repeat("v", MaxHookParameterValue), "6E", strings.Repeat("76", MaxHookParameterValue), true},
+		{strings.Repeat("n", MaxHookParameterName+1) + "=v", "", "", false},
+		{"n=" + strings.Repeat("v", MaxHookParameterValue+1), "", "", false},
+		{"amount", "", "", false},
+		{"=10", "", "", false},
+		{"0x=10", "", "", false},
+		{"0xZZ=10", "", "", false},
+		{"n=0xABC", "", "", false},
+	} {
+		param, err := HookParameter(test.in)
+		if (err == nil) != test.ok {
+			t.Errorf("HookParameter(%q) = %v, %v, want ok %v", test.in, param, err, test.ok)
+			continue
+		}
+		if !test.ok {
+			continue
+		}
+		p := param["HookParameter"].(map[string]interface{})
+		if p["HookParameterName"] != test.name || p["HookParameterValue"] != test.value {
+			t.Errorf("HookParameter(%q) = %v, want %s=%s", test.in, p, test.name, test.value)
+		}
+	}
+}
+
+func TestAddHookParameters(t *testing.T) {
+	hooks := &Definitions{Fields: map[string]FieldDefinition{"HookParameters": {}}}
+	tx := map[string]interface{}{
+		"HookParameters": []interface{}{
+			map[string]interface{}{"HookParameter": map[string]interface{}{"HookParameterName": "61", "HookParameterValue": "01"}},
+		},
+	}
+	if err := hooks.AddHookParameters(tx, []string{"b=2", "c=3"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(tx["HookParameters"].([]interface{})); n != 3 {
+		t.Errorf("%d hook parameters, want 3", n)
+	}
+	// a is already given, as hex
+	if err := hooks.AddHookParameters(tx, []string{"0x61=1"}); err == nil {
+		t.Error("A repeated parameter was added")
+	}
+
+	var many []string
+	for i := 0; i <= MaxHookParameters; i++ {
+		many = append(many, fmt.Sprintf("p%d=1", i))
+	}
+	if err := hooks.AddHookParameters(map[string]interface{}{}, many); err == nil {
+		t.Errorf("%d parameters were added", len(many))
+	}
+	if err := hooks.AddHookParameters(map[string]interface{}{}, many[:MaxHookParameters]); err != nil {
+		t.Error(err)
+	}
+
+	noHooks := &Definitions{Fields: map[string]FieldDefinition{}}
+	if err := noHooks.AddHookParameters(map[string]interface{}{}, []string{"a=1"}); err == nil {
+		t.Error("Parameters were added without hooks")
+	}
+	if err := noHooks.AddHookParameters(map[string]interface{}{}, nil); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,47 @@
+package txlib
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/rubblelabs/ripple/data"
+)
+
+// LedgerTOMLPath is where a domain publishes its xrp-ledger.toml.
+const LedgerTOMLPath = "/.well-known/xrp-ledger.toml"
+
+var ledgerTOMLClient = &http.Client{Timeout: 10 * time.Second}
+
+type ledgerTOML struct {
+	Accounts []struct {
+		Address string `toml:"address"`
+	} `toml:"ACCOUNTS"`
+}
+
+// VerifyDomain fetches the xrp-ledger.toml for domain over HTTPS and checks
+// that it lists account. Together with the account's Domain field this
+// shows the domain and the account are run by the same people.
+func VerifyDomain(domain string, account data.Account) error {
+	domain = strings.TrimSuffix(strings.ToLower(domain), "/")
+	resp, err := ledgerTOMLClient.Get("https://" + domain + LedgerTOMLPath)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Fetching %s%s: %s", domain, LedgerTOMLPath, resp.Status)
+	}
+	var t ledgerTOML
+	if _, err := toml.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return fmt.Errorf("Reading %s%s: %s", domain, LedgerTOMLPath, err)
+	}
+	for _, a := range t.Accounts {
+		if a.Address == account.String() {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s%s does not list %s", domain, LedgerTOMLPath, account)
+}
@@ -0,0 +1,60 @@
+package txlib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rubblelabs/ripple/data"
+)
+
+// TxFactory returns the constructor for the named transaction type, or nil.
+// Unknown names map to the zero TransactionType, which is a Payment, so the
+// name is checked against what the factory builds.
+func TxFactory(name string) func() data.Transaction {
+	factory := data.GetTxFactoryByType(name)
+	if factory == nil || factory().GetType() != name {
+		return nil
+	}
+	return factory
+}
+
+// ParseTransactionJSON decodes a transaction in rippled's JSON form. Time
+// fields may be given as RFC 3339 strings or durations, as ParseRippleTime
+// reads them.
+func ParseTransactionJSON(b []byte) (data.Transaction, error) {
+	var typ struct{ TransactionType string }
+	if err := json.Unmarshal(b, &typ); err != nil {
+		return nil, err
+	}
+	factory := TxFactory(typ.TransactionType)
+	if factory == nil {
+		return nil, fmt.Errorf("Unknown transaction type: %q", typ.TransactionType)
+	}
+	b, err := ConvertTimes(b)
+	if err != nil {
+		return nil, err
+	}
+	tx := factory()
+	if err := json.Unmarshal(b, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// DecodeTransaction parses a binary transaction. The hash is only filled in
+// for signed transactions, as it changes once a signature is added.
+func DecodeTransaction(b []byte) (data.Transaction, error) {
+	tx, err := data.ReadTransaction(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	if sig := tx.GetBase().TxnSignature; sig != nil && len(*sig) > 0 {
+		hash, _, err := data.Raw(tx)
+		if err != nil {
+			return nil, err
+		}
+		*tx.GetHash() = hash
+	}
+	return tx, nil
+}
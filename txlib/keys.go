@@ -0,0 +1,144 @@
+package txlib
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/rubblelabs/ripple/crypto"
+	"github.com/rubblelabs/ripple/data"
+)
+
+// Seeds generated for ed25519 are encoded with this prefix, so they read
+// sEd... rather than s...
+var ed25519SeedPrefix = []byte{0x01, 0xE1, 0x4B}
+
+// ParseSeed decodes a family seed to its 16 bytes of entropy, and reports
+// whether it is encoded as an ed25519 seed.
+func ParseSeed(s string) ([]byte, bool, error) {
+	if strings.HasPrefix(s, "sEd") {
+		b, err := crypto.Base58Decode(s, crypto.ALPHABET)
+		if err != nil {
+			return nil, false, err
+		}
+		b = b[:len(b)-4]
+		if len(b) != len(ed25519SeedPrefix)+16 || !bytes.Equal(b[:3], ed25519SeedPrefix) {
+			return nil, false, fmt.Errorf("Bad ed25519 seed: %s", s)
+		}
+		return b[3:], true, nil
+	}
+	seed, err := crypto.NewRippleHashCheck(s, crypto.RIPPLE_FAMILY_SEED)
+	if err != nil {
+		return nil, false, err
+	}
+	return seed.Payload(), false, nil
+}
+
+// EncodeSeed encodes 16 bytes of entropy as a family seed, as sEd... for
+// ed25519 so that it is not mistaken for a secp256k1 seed later.
+func EncodeSeed(entropy []byte, ed25519 bool) (string, error) {
+	if ed25519 {
+		return crypto.Base58Encode(append(append([]byte{}, ed25519SeedPrefix...), entropy...), crypto.ALPHABET), nil
+	}
+	seed, err := crypto.NewFamilySeed(entropy)
+	if err != nil {
+		return "", err
+	}
+	return seed.String(), nil
+}
+
+// NewKey derives the signing key for a seed's entropy. ECDSA keys use the
+// first account of the family, ed25519 keys have no sequence.
+func NewKey(entropy []byte, ed25519 bool) (crypto.Key, *uint32, error) {
+	if ed25519 {
+		k, err := crypto.NewEd25519Key(entropy)
+		return k, nil, err
+	}
+	k, err := crypto.NewECDSAKey(entropy)
+	seq := uint32(0)
+	return k, &seq, err
+}
+
+// KeyAccount returns the account a key signs for.
+func KeyAccount(key crypto.Key, seq *uint32) data.Account {
+	var account data.Account
+	copy(account[:], key.Id(seq))
+	return account
+}
+
+// IsEd25519 reports whether a key is an ed25519 key, whose public keys
+// start with 0xED.
+func IsEd25519(key crypto.Key, seq *uint32) bool {
+	return key.Public(seq)[0] == 0xED
+}
+
+// Secp256k1Key is a single key pair rather than a family derived from a
+// seed, so it has no key sequences.
+type Secp256k1Key struct {
+	*btcec.PrivateKey
+}
+
+func (k Secp256k1Key) Private(*uint32) []byte {
+	b := k.Key.Bytes()
+	return b[:]
+}
+
+func (k Secp256k1Key) Public(*uint32) []byte {
+	return k.PubKey().SerializeCompressed()
+}
+
+func (k Secp256k1Key) Id(seq *uint32) []byte {
+	return crypto.Sha256RipeMD160(k.Public(seq))
+}
+
+// Ed25519Key is an ed25519 key pair given directly rather than by a seed.
+type Ed25519Key struct {
+	ed25519.PrivateKey
+}
+
+func (k Ed25519Key) Private(*uint32) []byte {
+	return k.PrivateKey
+}
+
+func (k Ed25519Key) Public(*uint32) []byte {
+	return append([]byte{0xED}, k.PrivateKey[32:]...)
+}
+
+func (k Ed25519Key) Id(seq *uint32) []byte {
+	return crypto.Sha256RipeMD160(k.Public(seq))
+}
+
+// ParseSecretHex decodes a raw private key. A 33 byte key is prefixed with
+// ED for ed25519 or 00 for secp256k1, as rippled prints them. A bare 32 byte
+// key is secp256k1 unless ed is set.
+func ParseSecretHex(s string, ed bool) (crypto.Key, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 33 {
+		switch b[0] {
+		case 0xED:
+			ed = true
+		case 0x00:
+			ed = false
+		default:
+			return nil, fmt.Errorf("Unknown private key prefix: %02X", b[0])
+		}
+		b = b[1:]
+	}
+	if len(b) != 32 {
+		return nil, fmt.Errorf("Private key must be 32 bytes, got %d", len(b))
+	}
+	if ed {
+		return Ed25519Key{ed25519.NewKeyFromSeed(b)}, nil
+	}
+	k, _ := btcec.PrivKeyFromBytes(b)
+	if k.Key.IsZero() {
+		return nil, fmt.Errorf("Invalid secp256k1 private key")
+	}
+	return Secp256k1Key{k}, nil
+}
@@ -0,0 +1,168 @@
+package txlib
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/rubblelabs/ripple/crypto"
+)
+
+// The prefix of the data signed for a validator manifest, "MAN\0"
+var manifestPrefix = []byte{'M', 'A', 'N', 0}
+
+// RevokedManifestSequence is the sequence of a manifest that revokes its
+// master key for good.
+const RevokedManifestSequence = 0xFFFFFFFF
+
+// Manifest is a validator manifest, which delegates validating from the
+// validator's master key to an ephemeral signing key.
+type Manifest struct {
+	Sequence        uint32
+	Version         *uint16
+	MasterKey       []byte
+	SigningKey      []byte
+	Signature       []byte
+	MasterSignature []byte
+	Domain          []byte
+	// The fields that are signed, with the prefix
+	signed []byte
+}
+
+// The type codes and field numbers a manifest uses
+const (
+	manifestUInt16 = 1
+	manifestUInt32 = 2
+	manifestBlob   = 7
+
+	fieldVersion         = 16
+	fieldSequence        = 4
+	fieldPublicKey       = 1
+	fieldSigningPubKey   = 3
+	fieldSignature       = 6
+	fieldDomain          = 7
+	fieldMasterSignature = 18
+)
+
+// ParseManifest decodes a manifest as validators publish it, once base64
+// is removed.
+func ParseManifest(b []byte) (*Manifest, error) {
+	m := &Manifest{signed: append([]byte{}, manifestPrefix...)}
+	haveSequence := false
+	for len(b) > 0 {
+		start := b
+		typ, nth := int(b[0]>>4), int(b[0]&0x0F)
+		b = b[1:]
+		if typ == 0 {
+			if len(b) == 0 {
+				return nil, fmt.Errorf("Manifest ends in a field header")
+			}
+			typ, b = int(b[0]), b[1:]
+		}
+		if nth == 0 {
+			if len(b) == 0 {
+				return nil, fmt.Errorf("Manifest ends in a field header")
+			}
+			nth, b = int(b[0]), b[1:]
+		}
+		var value []byte
+		switch typ {
+		case manifestUInt16:
+			if len(b) < 2 {
+				return nil, fmt.Errorf("Manifest ends in a UInt16")
+			}
+			value, b = b[:2], b[2:]
+		case manifestUInt32:
+			if len(b) < 4 {
+				return nil, fmt.Errorf("Manifest ends in a UInt32")
+			}
+			value, b = b[:4], b[4:]
+		case manifestBlob:
+			n, rest, err := readVLLength(b)
+			if err != nil {
+				return nil, err
+			}
+			if len(rest) < n {
+				return nil, fmt.Errorf("Manifest ends in a %d byte field", n)
+			}
+			value, b = rest[:n], rest[n:]
+		default:
+			return nil, fmt.Errorf("Manifest has a field of unknown type %d", typ)
+		}
+		field := start[:len(start)-len(b)]
+
+		switch {
+		case typ == manifestUInt16 && nth == fieldVersion:
+			version := binary.BigEndian.Uint16(value)
+			m.Version = &version
+		case typ == manifestUInt32 && nth == fieldSequence:
+			m.Sequence = binary.BigEndian.Uint32(value)
+			haveSequence = true
+		case typ == manifestBlob && nth == fieldPublicKey:
+			m.MasterKey = value
+		case typ == manifestBlob && nth == fieldSigningPubKey:
+			m.SigningKey = value
+		case typ == manifestBlob && nth == fieldSignature:
+			m.Signature = value
+		case typ == manifestBlob && nth == fieldMasterSignature:
+			m.MasterSignature = value
+		case typ == manifestBlob && nth == fieldDomain:
+			m.Domain = value
+		default:
+			return nil, fmt.Errorf("Manifest has unknown field %d of type %d", nth, typ)
+		}
+		// Neither signature is signed.
+		if typ != manifestBlob || (nth != fieldSignature && nth != fieldMasterSignature) {
+			m.signed = append(m.signed, field...)
+		}
+	}
+	if !haveSequence || m.MasterKey == nil || m.MasterSignature == nil {
+		return nil, fmt.Errorf("Manifest needs a Sequence, PublicKey and MasterSignature")
+	}
+	return m, nil
+}
+
+// readVLLength reads the length prefix of a variable length field.
+func readVLLength(b []byte) (int, []byte, error) {
+	if len(b) == 0 {
+		return 0, nil, fmt.Errorf("Manifest ends in a length")
+	}
+	switch b1 := int(b[0]); {
+	case b1 <= 192:
+		return b1, b[1:], nil
+	case b1 <= 240:
+		if len(b) < 2 {
+			return 0, nil, fmt.Errorf("Manifest ends in a length")
+		}
+		return 193 + (b1-193)*256 + int(b[1]), b[2:], nil
+	case b1 <= 254:
+		if len(b) < 3 {
+			return 0, nil, fmt.Errorf("Manifest ends in a length")
+		}
+		return 12481 + (b1-241)*65536 + int(b[1])*256 + int(b[2]), b[3:], nil
+	}
+	return 0, nil, fmt.Errorf("Bad length prefix %d", b[0])
+}
+
+// Revoked reports whether the manifest revokes its master key.
+func (m *Manifest) Revoked() bool {
+	return m.Sequence == RevokedManifestSequence
+}
+
+func verifyManifest(pub, sig, msg []byte) bool {
+	if len(pub) == 0 || len(sig) == 0 {
+		return false
+	}
+	ok, err := crypto.Verify(pub, crypto.Sha512Half(msg), msg, sig)
+	return err == nil && ok
+}
+
+// VerifyMaster checks the master key's signature of the manifest.
+func (m *Manifest) VerifyMaster() bool {
+	return verifyManifest(m.MasterKey, m.MasterSignature, m.signed)
+}
+
+// VerifySigning checks the signing key's signature of the manifest. A
+// revocation has neither.
+func (m *Manifest) VerifySigning() bool {
+	return verifyManifest(m.SigningKey, m.Signature, m.signed)
+}
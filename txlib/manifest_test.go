@@ -0,0 +1,86 @@
+package txlib
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// Manifests signed for these tests with ed25519 keys: sequence 3 for
+// example.com, and a revocation of the same master key.
+const (
+	testManifest   = "24000000037121ed30e9755cb7c26454d6136b46b9189b56ac695f3a0b520a19551b535618a4a4997321ed158f58efdd7865262bc9164296d62f7c1ae167f6602eb89d81d3c31d71b3d8177640317ee5d735ebf0d3b3dc44e980950120e6e531b71b8eda2c1d0ba9fc2be7690b0911633c322ca2fbf8fb8d17ac0b8bbcc284f6afa71c7140c788c6583367cc02770b6578616d706c652e636f6d701240a8ab8bdb6a09a65bb7abf03e0fea1f805b8cb2a0e23a6a7e55c11e802a6f69d8368a2bb0f3edcae3f456c5c0ceba9b54b3ce8efc2b70408ee68caf9a267e6501"
+	testRevocation = "24ffffffff7121ed30e9755cb7c26454d6136b46b9189b56ac695f3a0b520a19551b535618a4a499701240242d98cc9633d49b58d64ffb65b34077f0a26c44b713b4d9e7545f311c2adc9501a805a3626075693d1897b6baff36c68d7519f2208ce1aabb8e9602a2c50404"
+
+	testMasterKey  = "ed30e9755cb7c26454d6136b46b9189b56ac695f3a0b520a19551b535618a4a499"
+	testSigningKey = "ed158f58efdd7865262bc9164296d62f7c1ae167f6602eb89d81d3c31d71b3d817"
+)
+
+func parseTestManifest(t *testing.T, s string) *Manifest {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := ParseManifest(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestParseManifest(t *testing.T) {
+	m := parseTestManifest(t, testManifest)
+	if m.Sequence != 3 || m.Version != nil || string(m.Domain) != "example.com" || m.Revoked() {
+		t.Errorf("Manifest is sequence %d, version %v, domain %q, revoked %v", m.Sequence, m.Version, m.Domain, m.Revoked())
+	}
+	if hex.EncodeToString(m.MasterKey) != testMasterKey || hex.EncodeToString(m.SigningKey) != testSigningKey {
+		t.Errorf("Manifest keys are %X and %X", m.MasterKey, m.SigningKey)
+	}
+	if !m.VerifyMaster() || !m.VerifySigning() {
+		t.Errorf("Manifest signatures are master %v, signing %v", m.VerifyMaster(), m.VerifySigning())
+	}
+
+	r := parseTestManifest(t, testRevocation)
+	if !r.Revoked() || r.SigningKey != nil || !r.VerifyMaster() || r.VerifySigning() {
+		t.Errorf("Revocation is revoked %v, master %v, signing %v", r.Revoked(), r.VerifyMaster(), r.VerifySigning())
+	}
+}
+
+func TestParseManifestTampered(t *testing.T) {
+	// The domain changed to exbmple.com
+	b, _ := hex.DecodeString(testManifest)
+	b[len(b)-len("ample.com")-67] = 'b'
+	m, err := ParseManifest(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(m.Domain) != "exbmple.com" {
+		t.Fatalf("Domain is %q", m.Domain)
+	}
+	if m.VerifyMaster() || m.VerifySigning() {
+		t.Error("A changed manifest verifies")
+	}
+}
+
+func TestParseManifestErrors(t *testing.T) {
+	for _, test := range []string{
+		"",
+		// No master signature
+		testManifest[:len(testManifest)-134],
+		// Cut short in a signature
+		testManifest[:len(testManifest)-2],
+		// Cut short in a header
+		testManifest[:len(testManifest)-134] + "70",
+		// Unknown type 8
+		"8100" + testManifest,
+		// Unknown UInt32 field 5
+		"2500000000" + testManifest,
+	} {
+		b, err := hex.DecodeString(test)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ParseManifest(b); err == nil {
+			t.Errorf("ParseManifest(%s) succeeded", test)
+		}
+	}
+}
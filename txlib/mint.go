@@ -0,0 +1,111 @@
+package txlib
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/rubblelabs/ripple/data"
+)
+
+// NFTokenTransferable is the NFTokenMint flag, kept in the NFTokenID, that
+// lets holders transfer a token.
+const NFTokenTransferable = 0x0008
+
+// MintRow is one token of a mint manifest. The transfer fee is a
+// percentage, as ParseTransferFee reads it. Key names the key to sign with,
+// empty for the default key.
+type MintRow struct {
+	URI         string `json:"uri"`
+	Taxon       uint32 `json:"taxon"`
+	TransferFee string `json:"transfer_fee,omitempty"`
+	Key         string `json:"key,omitempty"`
+}
+
+// Mint is an unsigned mint of a manifest and the name of the key to sign it
+// with.
+type Mint struct {
+	Mint *data.NFTokenMint
+	Key  string
+}
+
+// ReadMintManifest parses a manifest of tokens into unsigned mints. It is
+// either a JSON array of MintRow objects or CSV rows of uri, taxon and
+// optionally a transfer fee and key, where a header row starting with "uri"
+// is skipped. Tokens with a transfer fee are made transferable.
+func ReadMintManifest(r io.Reader) ([]Mint, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var rows []MintRow
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("[")) {
+		if err := json.Unmarshal(b, &rows); err != nil {
+			return nil, err
+		}
+	} else if rows, err = readMintCSV(bytes.NewReader(b)); err != nil {
+		return nil, err
+	}
+
+	var mints []Mint
+	for i, row := range rows {
+		mint := NewNFTokenMint(row.Taxon)
+		if row.URI != "" {
+			uri, err := NFTokenURI(row.URI)
+			if err != nil {
+				return nil, fmt.Errorf("Row %d: %s", i+1, err)
+			}
+			mint.URI = new(data.VariableLength)
+			*mint.URI = data.VariableLength(uri)
+		}
+		if row.TransferFee != "" {
+			fee, err := ParseTransferFee(row.TransferFee)
+			if err != nil {
+				return nil, fmt.Errorf("Row %d: %s", i+1, err)
+			}
+			if fee > 0 {
+				mint.TransferFee = &fee
+				*mint.Flags = *mint.Flags | NFTokenTransferable
+			}
+		}
+		mints = append(mints, Mint{Mint: mint, Key: row.Key})
+	}
+	return mints, nil
+}
+
+func readMintCSV(r io.Reader) ([]MintRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > 0 && strings.EqualFold(records[0][0], "uri") {
+		records = records[1:]
+	}
+	var rows []MintRow
+	for i, record := range records {
+		if len(record) < 2 || len(record) > 4 {
+			return nil, fmt.Errorf("Row %d: expected uri, taxon, transfer fee and key, got %d columns", i+1, len(record))
+		}
+		taxon, err := strconv.ParseUint(record[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Row %d: bad taxon: %s", i+1, record[1])
+		}
+		row := MintRow{URI: record[0], Taxon: uint32(taxon)}
+		if len(record) > 2 {
+			row.TransferFee = record[2]
+		}
+		if len(record) > 3 {
+			row.Key = record[3]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,137 @@
+package txlib
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+// The default BIP44 path for the first XRP Ledger account.
+const DefaultHDPath = "m/44'/144'/0'/0/0"
+
+const hardened = 0x80000000
+
+// MnemonicSeed returns the BIP39 seed for a phrase. The phrase must be
+// English BIP39 words with a valid checksum, so a mistyped word is an error
+// rather than the seed of an empty account. Both the phrase and passphrase
+// are NFKD normalized, as BIP39 requires.
+func MnemonicSeed(mnemonic, passphrase string) ([]byte, error) {
+	mnemonic = strings.Join(strings.Fields(norm.NFKD.String(mnemonic)), " ")
+	if err := CheckMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+	salt := "mnemonic" + norm.NFKD.String(passphrase)
+	return pbkdf2.Key([]byte(mnemonic), []byte(salt), 2048, 64, sha512.New), nil
+}
+
+// CheckMnemonic checks a phrase is 12 to 24 English BIP39 words ending in
+// the checksum of the entropy they encode.
+func CheckMnemonic(mnemonic string) error {
+	words := strings.Fields(mnemonic)
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return fmt.Errorf("A BIP39 phrase has 12, 15, 18, 21 or 24 words, got %d", len(words))
+	}
+	bits := make([]bool, 0, len(words)*11)
+	for i, word := range words {
+		index := bip39Index(word)
+		if index < 0 {
+			return fmt.Errorf("Word %d, %s, is not in the BIP39 English word list", i+1, word)
+		}
+		for b := 10; b >= 0; b-- {
+			bits = append(bits, index>>uint(b)&1 == 1)
+		}
+	}
+	// Each 3 words hold 32 bits of entropy and 1 of checksum.
+	checksumBits := len(words) / 3
+	entropy := make([]byte, (len(bits)-checksumBits)/8)
+	for i := range entropy {
+		for b := 0; b < 8; b++ {
+			if bits[i*8+b] {
+				entropy[i] |= 0x80 >> uint(b)
+			}
+		}
+	}
+	sum := sha256.Sum256(entropy)
+	for i := 0; i < checksumBits; i++ {
+		if bits[len(entropy)*8+i] != (sum[i/8]&(0x80>>uint(i%8)) != 0) {
+			return fmt.Errorf("The BIP39 phrase checksum does not match, a word is wrong or out of order")
+		}
+	}
+	return nil
+}
+
+// bip39Index returns the position of a word in the BIP39 list, or -1.
+func bip39Index(word string) int {
+	i := sort.SearchStrings(bip39Words[:], word)
+	if i < len(bip39Words) && bip39Words[i] == word {
+		return i
+	}
+	return -1
+}
+
+// ParseHDPath parses a BIP32 derivation path like m/44'/144'/0'/0/0.
+func ParseHDPath(path string) ([]uint32, error) {
+	parts := strings.Split(path, "/")
+	if parts[0] != "m" {
+		return nil, fmt.Errorf("Derivation path must start with m: %s", path)
+	}
+	var indexes []uint32
+	for _, part := range parts[1:] {
+		offset := uint32(0)
+		if strings.HasSuffix(part, "'") || strings.HasSuffix(part, "h") {
+			part, offset = part[:len(part)-1], hardened
+		}
+		i, err := strconv.ParseUint(part, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("Bad derivation path: %s", path)
+		}
+		indexes = append(indexes, uint32(i)+offset)
+	}
+	return indexes, nil
+}
+
+// DeriveHDKey follows BIP32 from a BIP39 seed down the path.
+func DeriveHDKey(seed []byte, path []uint32) (*btcec.PrivateKey, error) {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	I := mac.Sum(nil)
+
+	var k btcec.ModNScalar
+	if k.SetByteSlice(I[:32]) || k.IsZero() {
+		return nil, fmt.Errorf("Invalid master key, try another seed")
+	}
+	chainCode := I[32:]
+	for _, index := range path {
+		var data []byte
+		if index >= hardened {
+			b := k.Bytes()
+			data = append([]byte{0}, b[:]...)
+		} else {
+			data = btcec.PrivKeyFromScalar(&k).PubKey().SerializeCompressed()
+		}
+		data = binary.BigEndian.AppendUint32(data, index)
+
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		I := mac.Sum(nil)
+		var tweak btcec.ModNScalar
+		if tweak.SetByteSlice(I[:32]) {
+			return nil, fmt.Errorf("Invalid child key at index %d", index)
+		}
+		k.Add(&tweak)
+		if k.IsZero() {
+			return nil, fmt.Errorf("Invalid child key at index %d", index)
+		}
+		chainCode = I[32:]
+	}
+	return btcec.PrivKeyFromScalar(&k), nil
+}
@@ -0,0 +1,168 @@
+package txlib
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// From the BIP39 reference vectors, with the passphrase TREZOR
+var mnemonicVectors = []struct {
+	mnemonic string
+	seed     string
+}{
+	{
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+	},
+	{
+		"legal winner thank year wave sausage worth useful legal winner thank yellow",
+		"2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607",
+	},
+}
+
+func TestMnemonicSeed(t *testing.T) {
+	for _, test := range mnemonicVectors {
+		seed, err := MnemonicSeed(test.mnemonic, "TREZOR")
+		if err != nil {
+			t.Errorf("MnemonicSeed(%q): %s", test.mnemonic, err)
+			continue
+		}
+		if got := hex.EncodeToString(seed); got != test.seed {
+			t.Errorf("MnemonicSeed(%q) = %s, want %s", test.mnemonic, got, test.seed)
+		}
+	}
+}
+
+func TestMnemonicSeedSpacing(t *testing.T) {
+	a, err := MnemonicSeed(mnemonicVectors[0].mnemonic, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := MnemonicSeed("  "+strings.Replace(mnemonicVectors[0].mnemonic, " ", "\n ", -1)+"\n", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a, b) {
+		t.Error("Whitespace between words changed the seed")
+	}
+}
+
+func TestMnemonicSeedNFKD(t *testing.T) {
+	// é written as one code point and as e and a combining accent
+	composed, err := MnemonicSeed(mnemonicVectors[0].mnemonic, "caf\u00e9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	decomposed, err := MnemonicSeed(mnemonicVectors[0].mnemonic, "cafe\u0301")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(composed, decomposed) {
+		t.Error("The passphrase is not NFKD normalized")
+	}
+}
+
+func TestCheckMnemonic(t *testing.T) {
+	for _, test := range []struct {
+		mnemonic string
+		ok       bool
+	}{
+		{"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", true},
+		{"letter advice cage absurd amount doctor acoustic avoid letter advice cage above", true},
+		{"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong", true},
+		{"void come effort suffer camp survey warrior heavy shoot primary clutch crush open amazing screen patrol group space point ten exist slush involve unfold", true},
+		// The last word carries the checksum.
+		{"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon", false},
+		// Two words swapped
+		{"winner legal thank year wave sausage worth useful legal winner thank yellow", false},
+		// A mistyped word
+		{"legal winner thank year wave sausage worth usefull legal winner thank yellow", false},
+		{"abandon abandon abandon", false},
+		{"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", false},
+		{"", false},
+	} {
+		if err := CheckMnemonic(test.mnemonic); (err == nil) != test.ok {
+			t.Errorf("CheckMnemonic(%q) = %v, want ok %v", test.mnemonic, err, test.ok)
+		}
+	}
+}
+
+func TestParseHDPath(t *testing.T) {
+	for _, test := range []struct {
+		path string
+		want []uint32
+		ok   bool
+	}{
+		{"m", nil, true},
+		{"m/0", []uint32{0}, true},
+		{"m/44'/144'/0'/0/0", []uint32{hardened + 44, hardened + 144, hardened, 0, 0}, true},
+		{"m/44h/144h/0h/0/7", []uint32{hardened + 44, hardened + 144, hardened, 0, 7}, true},
+		{"m/2147483647'", []uint32{0xFFFFFFFF}, true},
+		{"m/2147483648", nil, false},
+		{"44'/144'/0'/0/0", nil, false},
+		{"m/", nil, false},
+		{"m/-1", nil, false},
+		{"m/x", nil, false},
+		{"m/0''", nil, false},
+		{"", nil, false},
+	} {
+		got, err := ParseHDPath(test.path)
+		if (err == nil) != test.ok {
+			t.Errorf("ParseHDPath(%q) = %v, %v, want ok %v", test.path, got, err, test.ok)
+			continue
+		}
+		if fmt.Sprint(got) != fmt.Sprint(test.want) {
+			t.Errorf("ParseHDPath(%q) = %v, want %v", test.path, got, test.want)
+		}
+	}
+}
+
+// From the BIP32 test vectors 1 and 2
+var hdKeyVectors = []struct {
+	seed string
+	path string
+	key  string
+}{
+	{"000102030405060708090a0b0c0d0e0f", "m", "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"},
+	{"000102030405060708090a0b0c0d0e0f", "m/0'", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+	{"000102030405060708090a0b0c0d0e0f", "m/0'/1", "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+	{"000102030405060708090a0b0c0d0e0f", "m/0'/1/2'", "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca"},
+	{"000102030405060708090a0b0c0d0e0f", "m/0'/1/2'/2", "0f479245fb19a38a1954c5c7c0ebab2f9bdfd96a17563ef28a6a4b1a2a764ef4"},
+	{"000102030405060708090a0b0c0d0e0f", "m/0'/1/2'/2/1000000000", "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+	{
+		"fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		"m", "4b03d6fc340455b363f51020ad3ecca4f0850280cf436c70c727923f6db46c3e",
+	},
+	{
+		"fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		"m/0", "abe74a98f6c7eabee0428f53798f0ab8aa1bd37873999041703c742f15ac7e1e",
+	},
+	{
+		"fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		"m/0/2147483647'/1", "704addf544a06e5ee4bea37098463c23613da32020d604506da8c0518e1da4b7",
+	},
+}
+
+func TestDeriveHDKey(t *testing.T) {
+	for _, test := range hdKeyVectors {
+		seed, err := hex.DecodeString(test.seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path, err := ParseHDPath(test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := DeriveHDKey(seed, path)
+		if err != nil {
+			t.Errorf("DeriveHDKey(%s, %s): %s", test.seed, test.path, err)
+			continue
+		}
+		if got := hex.EncodeToString(key.Serialize()); got != test.key {
+			t.Errorf("DeriveHDKey(%s, %s) = %s, want %s", test.seed, test.path, got, test.key)
+		}
+	}
+}
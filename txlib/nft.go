@@ -0,0 +1,86 @@
+package txlib
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// MaxURILength is the most bytes an NFToken URI can hold.
+const MaxURILength = 256
+
+// MaxCIDFileSize is the largest file FileCID gives the same CID for as
+// ipfs add --cid-version=1 --raw-leaves. Larger files are split into
+// chunks, which changes the CID.
+const MaxCIDFileSize = 256 * 1024
+
+// MaxTransferFee is the highest NFToken transfer fee, 50%, in the
+// thousandths of a percent TransferFee uses.
+const MaxTransferFee = 50000
+
+// ParseTransferFee reads a transfer fee as a percentage, with or without a
+// trailing %, and returns it in thousandths of a percent. The decimal is
+// read as written, so every 0.001% step is exact.
+func ParseTransferFee(s string) (uint16, error) {
+	p := strings.TrimSuffix(strings.TrimSpace(s), "%")
+	whole, frac := p, ""
+	if i := strings.Index(p, "."); i >= 0 {
+		whole, frac = p[:i], p[i+1:]
+	}
+	if whole == "" && frac == "" {
+		return 0, fmt.Errorf("Bad transfer fee %s", s)
+	}
+	// Trailing zeros past the third place are no more precise.
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > 3 {
+		return 0, fmt.Errorf("Transfer fee %s is too precise, the smallest step is 0.001%%", s)
+	}
+	digits := whole + frac + strings.Repeat("0", 3-len(frac))
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("Bad transfer fee %s", s)
+		}
+	}
+	fee, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil || fee > MaxTransferFee {
+		return 0, fmt.Errorf("Transfer fee %s is out of range, it must be from 0%% to 50%%", s)
+	}
+	return uint16(fee), nil
+}
+
+// NFTokenURI checks a token URI, such as an https:// or ipfs:// link to the
+// token's metadata, and returns the bytes to store. The ledger keeps them as
+// is, and they show as hex in JSON.
+func NFTokenURI(s string) ([]byte, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" {
+		return nil, fmt.Errorf("URI %s has no scheme, such as https:// or ipfs://", s)
+	}
+	if u.Scheme == "ipfs" && u.Host == "" {
+		return nil, fmt.Errorf("URI %s has no CID", s)
+	}
+	if len(s) > MaxURILength {
+		return nil, fmt.Errorf("URI is %d bytes, the most an NFToken can hold is %d", len(s), MaxURILength)
+	}
+	return []byte(s), nil
+}
+
+// FileCID returns the CIDv1 of a file's contents, in the base32 form IPFS
+// gateways use. Files over MaxCIDFileSize are refused, as IPFS would chunk
+// them.
+func FileCID(b []byte) (string, error) {
+	if len(b) > MaxCIDFileSize {
+		return "", fmt.Errorf("File is %d bytes, over %d IPFS would split it into chunks. Add it with ipfs add and pass the URI instead", len(b), MaxCIDFileSize)
+	}
+	sum := sha256.Sum256(b)
+	// Version 1, the raw codec, then a sha2-256 multihash of 32 bytes
+	cid := append([]byte{0x01, 0x55, 0x12, 0x20}, sum[:]...)
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	return "b" + strings.ToLower(encoding.EncodeToString(cid)), nil
+}
@@ -0,0 +1,53 @@
+package txlib
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseTransferFee(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want uint16
+		ok   bool
+	}{
+		{"0", 0, true},
+		{"0%", 0, true},
+		{"1", 1000, true},
+		{"1.005", 1005, true},
+		{"1.005%", 1005, true},
+		{"0.001", 1, true},
+		{".5", 500, true},
+		{"2.", 2000, true},
+		{"12.3450", 12345, true},
+		{"50", 50000, true},
+		{"50.000", 50000, true},
+		{" 7.25% ", 7250, true},
+		{"50.001", 0, false},
+		{"51", 0, false},
+		{"0.0005", 0, false},
+		{"-1", 0, false},
+		{"", 0, false},
+		{".", 0, false},
+		{"%", 0, false},
+		{"1e3", 0, false},
+		{"1.2.3", 0, false},
+		{"abc", 0, false},
+		{"99999999999999999999", 0, false},
+	} {
+		got, err := ParseTransferFee(test.in)
+		if (err == nil) != test.ok || got != test.want {
+			t.Errorf("ParseTransferFee(%q) = %d, %v, want %d ok %v", test.in, got, err, test.want, test.ok)
+		}
+	}
+}
+
+func TestParseTransferFeeSteps(t *testing.T) {
+	for fee := 0; fee <= MaxTransferFee; fee++ {
+		s := fmt.Sprintf("%d.%03d", fee/1000, fee%1000)
+		got, err := ParseTransferFee(s)
+		if err != nil || int(got) != fee {
+			t.Fatalf("ParseTransferFee(%q) = %d, %v, want %d", s, got, err, fee)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package txlib
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/rubblelabs/ripple/data"
+)
+
+// Payout is an unsigned payment of a payout file and the name of the key
+// its row asks to sign it with, empty for the default key.
+type Payout struct {
+	Payment *data.Payment
+	Key     string
+}
+
+// ReadPayouts parses rows of destination, amount and optionally a
+// destination tag and memo into unsigned payments. A header row starting
+// with "destination" is skipped. Destinations are read with parse, or
+// ParseAddress if it is nil. A tag in the row replaces the tag parse gives,
+// unless it came from an X-address.
+func ReadPayouts(r io.Reader, parse func(string) (*data.Account, *uint32, error)) ([]*data.Payment, error) {
+	payouts, err := ReadPayoutKeys(r, parse)
+	if err != nil {
+		return nil, err
+	}
+	var payments []*data.Payment
+	for _, p := range payouts {
+		payments = append(payments, p.Payment)
+	}
+	return payments, nil
+}
+
+// ReadPayoutKeys is ReadPayouts for rows that may also name the key to
+// sign with, in a fifth column.
+func ReadPayoutKeys(r io.Reader, parse func(string) (*data.Account, *uint32, error)) ([]Payout, error) {
+	if parse == nil {
+		parse = ParseAddress
+	}
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) > 0 && strings.EqualFold(rows[0][0], "destination") {
+		rows = rows[1:]
+	}
+
+	var payouts []Payout
+	for i, row := range rows {
+		if len(row) < 2 || len(row) > 5 {
+			return nil, fmt.Errorf("Row %d: expected destination, amount, tag, memo and key, got %d columns", i+1, len(row))
+		}
+		destination, tag, err := parse(row[0])
+		if err != nil {
+			return nil, fmt.Errorf("Row %d: %s", i+1, err)
+		}
+		amount, err := data.NewAmount(row[1])
+		if err != nil {
+			return nil, fmt.Errorf("Row %d: %s", i+1, err)
+		}
+		payment := NewPayment(*destination, *amount)
+		if len(row) > 2 && row[2] != "" {
+			t, err := strconv.ParseUint(row[2], 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("Row %d: bad tag: %s", i+1, row[2])
+			}
+			if tag != nil && *tag != uint32(t) && IsXAddress(row[0]) {
+				return nil, fmt.Errorf("Row %d: tag %d conflicts with the X-address tag %d", i+1, t, *tag)
+			}
+			tag = new(uint32)
+			*tag = uint32(t)
+		}
+		payment.DestinationTag = tag
+		if len(row) > 3 && row[3] != "" {
+			payment.Memos = data.Memos{NewTextMemo(row[3])}
+		}
+		payout := Payout{Payment: payment}
+		if len(row) > 4 {
+			payout.Key = row[4]
+		}
+		payouts = append(payouts, payout)
+	}
+	return payouts, nil
+}
@@ -0,0 +1,63 @@
+package txlib
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rubblelabs/ripple/data"
+)
+
+func TestReadPayoutKeys(t *testing.T) {
+	csv := `destination,amount,tag,memo,key
+rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf,1.5/XRP
+rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf, 10/USD/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B, 7, "rent, May", treasury
+XVLhHMPHU98es4dbozjVtdWzVrDjtV8xvjGQTYPiAx6gwDC,2/XRP
+XVLhHMPHU98es4dbozjVtdWzVrDjtV8xvjGQTYPiAx6gwDC,2/XRP,1,,
+`
+	want := []struct {
+		amount string
+		tag    *uint32
+		memos  int
+		key    string
+	}{
+		{"1.5/XRP", nil, 0, ""},
+		{"10/USD/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", uint32Ptr(7), 1, "treasury"},
+		{"2/XRP", uint32Ptr(1), 0, ""},
+		{"2/XRP", uint32Ptr(1), 0, ""},
+	}
+	payouts, err := ReadPayoutKeys(strings.NewReader(csv), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payouts) != len(want) {
+		t.Fatalf("%d payouts, want %d", len(payouts), len(want))
+	}
+	for i, w := range want {
+		p := payouts[i]
+		amount, err := data.NewAmount(w.amount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Payment.Destination.String() != "rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf" || !p.Payment.Amount.Equals(*amount) ||
+			!sameTag(p.Payment.DestinationTag, w.tag) || len(p.Payment.Memos) != w.memos || p.Key != w.key {
+			t.Errorf("Row %d: %s %s tag %v, %d memos, key %q", i+1, p.Payment.Destination, &p.Payment.Amount, p.Payment.DestinationTag, len(p.Payment.Memos), p.Key)
+		}
+	}
+}
+
+func TestReadPayoutKeysErrors(t *testing.T) {
+	for _, csv := range []string{
+		"rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf\n",
+		"rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf,1/XRP,1,memo,key,extra\n",
+		"rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpe,1/XRP\n",
+		"rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf,lots\n",
+		"rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf,1/XRP,x\n",
+		// The tag conflicts with the X-address tag 1.
+		"XVLhHMPHU98es4dbozjVtdWzVrDjtV8xvjGQTYPiAx6gwDC,1/XRP,2\n",
+		"rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf,\"1/XRP\n",
+	} {
+		if _, err := ReadPayoutKeys(strings.NewReader(csv), nil); err == nil {
+			t.Errorf("ReadPayoutKeys(%q) succeeded", csv)
+		}
+	}
+}
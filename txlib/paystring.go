@@ -0,0 +1,81 @@
+package txlib
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rubblelabs/ripple/data"
+)
+
+var payStringClient = &http.Client{Timeout: 10 * time.Second}
+
+// IsPayString reports whether s is a PayString, as user$example.com.
+func IsPayString(s string) bool {
+	i := strings.LastIndex(s, "$")
+	return i > 0 && i < len(s)-1 && !strings.ContainsAny(s[i+1:], "/$")
+}
+
+type payStringResponse struct {
+	Addresses []struct {
+		PaymentNetwork string `json:"paymentNetwork"`
+		Environment    string `json:"environment"`
+		AddressDetails struct {
+			Address string `json:"address"`
+			Tag     string `json:"tag"`
+		} `json:"addressDetails"`
+	} `json:"addresses"`
+}
+
+// ResolvePayString looks up the XRPL address for a PayString over HTTPS.
+// The environment is mainnet, testnet or devnet. The address may be an
+// X-address, whose tag must then agree with any tag given separately.
+func ResolvePayString(s, environment string) (*data.Account, *uint32, error) {
+	if !IsPayString(s) {
+		return nil, nil, fmt.Errorf("Not a PayString: %s", s)
+	}
+	i := strings.LastIndex(s, "$")
+	req, err := http.NewRequest("GET", "https://"+s[i+1:]+"/"+s[:i], nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", "application/xrpl-"+environment+"+json")
+	req.Header.Set("PayID-Version", "1.0")
+	resp, err := payStringClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("Resolving %s: %s", s, resp.Status)
+	}
+	var result payStringResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, nil, fmt.Errorf("Resolving %s: %s", s, err)
+	}
+	for _, a := range result.Addresses {
+		if !strings.EqualFold(a.PaymentNetwork, "XRPL") || !strings.EqualFold(a.Environment, environment) {
+			continue
+		}
+		account, tag, err := ParseAddress(a.AddressDetails.Address)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Resolving %s: %s", s, err)
+		}
+		if a.AddressDetails.Tag != "" {
+			t, err := strconv.ParseUint(a.AddressDetails.Tag, 10, 32)
+			if err != nil {
+				return nil, nil, fmt.Errorf("Resolving %s: bad tag %s", s, a.AddressDetails.Tag)
+			}
+			if tag != nil && *tag != uint32(t) {
+				return nil, nil, fmt.Errorf("Resolving %s: tag %d conflicts with the X-address tag %d", s, t, *tag)
+			}
+			tag = new(uint32)
+			*tag = uint32(t)
+		}
+		return account, tag, nil
+	}
+	return nil, nil, fmt.Errorf("%s has no XRPL %s address", s, environment)
+}
@@ -0,0 +1,71 @@
+package txlib
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/rubblelabs/ripple/data"
+)
+
+// Values gives a Builder the values of its flags. *cli.Context implements
+// it.
+type Values interface {
+	String(name string) string
+	Bool(name string) bool
+	Int(name string) int
+	IsSet(name string) bool
+}
+
+// Flag is a command line flag of a Builder. Bool flags take no value.
+type Flag struct {
+	Name  string
+	Usage string
+	Value string
+	Bool  bool
+}
+
+// Builder adds a command to tx that builds a transaction from its flags.
+// The command signs and outputs the transaction like the built in ones, so
+// it must be of a type the data package can encode.
+type Builder struct {
+	Name    string
+	Aliases []string
+	Usage   string
+	Flags   []Flag
+	// Build returns the transaction for the flag values. The account,
+	// sequence and fee are filled in when it is signed.
+	Build func(v Values) (data.Transaction, error)
+}
+
+var (
+	buildersMu sync.Mutex
+	builders   = make(map[string]*Builder)
+)
+
+// Register adds a builder, usually from the init function of the package
+// or Go plugin defining it. It panics if a builder of the same name is
+// already registered.
+func Register(b *Builder) {
+	buildersMu.Lock()
+	defer buildersMu.Unlock()
+	if b.Name == "" || b.Build == nil {
+		panic("txlib: Register needs a Name and a Build function")
+	}
+	if _, dup := builders[b.Name]; dup {
+		panic(fmt.Sprintf("txlib: Register called twice for builder %s", b.Name))
+	}
+	builders[b.Name] = b
+}
+
+// Builders returns the registered builders sorted by name.
+func Builders() []*Builder {
+	buildersMu.Lock()
+	defer buildersMu.Unlock()
+	var list []*Builder
+	for _, b := range builders {
+		list = append(list, b)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
@@ -0,0 +1,103 @@
+package txlib
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The words of one to three letters come first in the RFC 1751 dictionary.
+const rfc1751ShortWords = 571
+
+// EncodeRFC1751 encodes 16 bytes of seed entropy as 12 RFC 1751 words, as
+// rippled shows a seed's master_key. rippled reverses the bytes first.
+func EncodeRFC1751(entropy []byte) (string, error) {
+	if len(entropy) != 16 {
+		return "", fmt.Errorf("Seed entropy must be 16 bytes, got %d", len(entropy))
+	}
+	return rfc1751Encode(reversed(entropy)), nil
+}
+
+// DecodeRFC1751 decodes 12 RFC 1751 words to the 16 bytes of seed entropy
+// they stand for, as rippled reads them. Case and spacing are ignored.
+func DecodeRFC1751(s string) ([]byte, error) {
+	words := strings.Fields(strings.ToUpper(s))
+	if len(words) != 12 {
+		return nil, fmt.Errorf("An RFC 1751 seed has 12 words, got %d", len(words))
+	}
+	b, err := rfc1751Decode(words)
+	if err != nil {
+		return nil, err
+	}
+	return reversed(b), nil
+}
+
+func reversed(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return r
+}
+
+// rfc1751Parity is the sum of the 2 bit pairs of a block, mod 4.
+func rfc1751Parity(block uint64) uint64 {
+	var parity uint64
+	for i := 0; i < 64; i += 2 {
+		parity += block >> uint(i) & 3
+	}
+	return parity & 3
+}
+
+// rfc1751Encode encodes each 8 bytes of b as 6 words: the 64 bits and 2
+// bits of parity, 11 bits a word.
+func rfc1751Encode(b []byte) string {
+	var words []string
+	for ; len(b) >= 8; b = b[8:] {
+		block := binary.BigEndian.Uint64(b)
+		// The top 64 of the 66 bits, then the last 2 with the parity.
+		for i := 0; i < 5; i++ {
+			words = append(words, rfc1751Words[block>>uint(53-11*i)&0x7FF])
+		}
+		words = append(words, rfc1751Words[(block&0x1FF)<<2|rfc1751Parity(block)])
+	}
+	return strings.Join(words, " ")
+}
+
+// rfc1751Decode decodes each 6 words to 8 bytes, checking their parity.
+func rfc1751Decode(words []string) ([]byte, error) {
+	var b []byte
+	for ; len(words) >= 6; words = words[6:] {
+		var bits uint64
+		for _, word := range words[:6] {
+			index := rfc1751Index(word)
+			if index < 0 {
+				return nil, fmt.Errorf("%s is not in the RFC 1751 dictionary", word)
+			}
+			bits = bits<<11 | uint64(index)
+		}
+		// The first 2 of the 66 bits were shifted out, so the top 11 are
+		// taken from the first word again.
+		block := uint64(rfc1751Index(words[0]))<<53 | bits>>2&(1<<53-1)
+		if bits&3 != rfc1751Parity(block) {
+			return nil, fmt.Errorf("The RFC 1751 parity does not match, a word is wrong or out of order")
+		}
+		b = binary.BigEndian.AppendUint64(b, block)
+	}
+	return b, nil
+}
+
+// rfc1751Index returns the position of an upper case word in the RFC 1751
+// dictionary, or -1.
+func rfc1751Index(word string) int {
+	words, offset := rfc1751Words[:rfc1751ShortWords], 0
+	if len(word) == 4 {
+		words, offset = rfc1751Words[rfc1751ShortWords:], rfc1751ShortWords
+	}
+	i := sort.SearchStrings(words, word)
+	if i < len(words) && words[i] == word {
+		return offset + i
+	}
+	return -1
+}
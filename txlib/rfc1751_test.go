@@ -0,0 +1,92 @@
+package txlib
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// From RFC 1751
+var rfc1751Vectors = []struct {
+	hex   string
+	words string
+}{
+	{"EB33F77EE73D4053", "TIDE ITCH SLOW REIN RULE MOT"},
+	{"CCAC2AED591056BE4F90FD441C534766", "RASH BUSH MILK LOOK BAD BRIM AVID GAFF BAIT ROT POD LOVE"},
+	{"EFF81F9BFBC65350920CDD7416DE8009", "TROD MUTE TAIL WARM CHAR KONG HAAG CITY BORE O TEAL AWL"},
+}
+
+func TestRFC1751Words(t *testing.T) {
+	seen := make(map[string]bool)
+	for i, word := range rfc1751Words {
+		if seen[word] || rfc1751Index(word) != i {
+			t.Errorf("Word %d, %s, is repeated or out of order", i, word)
+		}
+		seen[word] = true
+	}
+}
+
+func TestRFC1751Vectors(t *testing.T) {
+	for _, test := range rfc1751Vectors {
+		b, err := hex.DecodeString(test.hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := rfc1751Encode(b); got != test.words {
+			t.Errorf("rfc1751Encode(%s) = %s, want %s", test.hex, got, test.words)
+		}
+		got, err := rfc1751Decode(strings.Fields(test.words))
+		if err != nil {
+			t.Errorf("rfc1751Decode(%s): %s", test.words, err)
+			continue
+		}
+		if strings.ToUpper(hex.EncodeToString(got)) != test.hex {
+			t.Errorf("rfc1751Decode(%s) = %X, want %s", test.words, got, test.hex)
+		}
+	}
+}
+
+// The seed of rippled's wallet_propose for the passphrase masterpassphrase
+const (
+	testSeedHex   = "DEDCE9CE67B451D852FD4E846FCDE31C"
+	testSeedWords = "I IRE BOND BOW TRIO LAID SEAT GOAL HEN IBIS IBIS DARE"
+)
+
+func TestEncodeRFC1751(t *testing.T) {
+	entropy, _ := hex.DecodeString(testSeedHex)
+	got, err := EncodeRFC1751(entropy)
+	if err != nil || got != testSeedWords {
+		t.Errorf("EncodeRFC1751(%s) = %s, %v, want %s", testSeedHex, got, err, testSeedWords)
+	}
+	if _, err := EncodeRFC1751(entropy[:8]); err == nil {
+		t.Error("8 bytes were encoded as a seed")
+	}
+}
+
+func TestDecodeRFC1751(t *testing.T) {
+	for _, test := range []struct {
+		words string
+		hex   string
+		ok    bool
+	}{
+		{testSeedWords, testSeedHex, true},
+		{strings.ToLower(testSeedWords), testSeedHex, true},
+		{" I  IRE BOND BOW TRIO LAID\nSEAT GOAL HEN IBIS IBIS DARE ", testSeedHex, true},
+		// The parity of the second block does not match.
+		{"I IRE BOND BOW TRIO LAID SEAT GOAL HEN IBIS IBIS DART", "", false},
+		// Two words swapped
+		{"I IRE BOND BOW TRIO LAID SEAT GOAL IBIS HEN IBIS DARE", "", false},
+		{"I IRE BOND BOW TRIO LAID SEAT GOAL HEN IBIS IBIS XYZZY", "", false},
+		{"TIDE ITCH SLOW REIN RULE MOT", "", false},
+		{"", "", false},
+	} {
+		got, err := DecodeRFC1751(test.words)
+		if (err == nil) != test.ok {
+			t.Errorf("DecodeRFC1751(%q) = %X, %v, want ok %v", test.words, got, err, test.ok)
+			continue
+		}
+		if test.ok && strings.ToUpper(hex.EncodeToString(got)) != test.hex {
+			t.Errorf("DecodeRFC1751(%q) = %X, want %s", test.words, got, test.hex)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package txlib
+
+import (
+	"fmt"
+
+	"github.com/rubblelabs/ripple/crypto"
+	"github.com/rubblelabs/ripple/data"
+)
+
+// The fee in drops Prepare sets on a transaction without one
+const DefaultFee = 10
+
+// Options are the common fields Prepare fills in. Zero values are left out.
+type Options struct {
+	// Account signs the transaction with the key as its regular key. If
+	// nil, the account already on the transaction or else the key's own
+	// account is used.
+	Account *data.Account
+	// Sequence is used if the transaction has none.
+	Sequence uint32
+	// Fee replaces the transaction's fee. If nil, a zero fee is replaced
+	// with DefaultFee.
+	Fee *data.Value
+	// LastLedgerSequence is the highest ledger the transaction can be in.
+	LastLedgerSequence uint32
+	// AccountTxnID is set if the transaction has none.
+	AccountTxnID *data.Hash256
+	// NoCanonical leaves tfFullyCanonicalSig unset.
+	NoCanonical bool
+}
+
+// Prepare fills in the account, sequence, fee and flags of tx before it is
+// signed by key. The key may be nil when Options.Account is given, for a
+// transaction to be signed elsewhere.
+func Prepare(tx data.Transaction, key crypto.Key, seq *uint32, opts Options) error {
+	base := tx.GetBase()
+	if base.Sequence == 0 {
+		base.Sequence = opts.Sequence
+	}
+	switch {
+	case opts.Account != nil:
+		base.Account = *opts.Account
+	case base.Account.IsZero() && key != nil:
+		base.Account = KeyAccount(key, seq)
+	case base.Account.IsZero():
+		return fmt.Errorf("An account or key is required")
+	}
+	if base.AccountTxnID == nil {
+		base.AccountTxnID = opts.AccountTxnID
+	}
+	if opts.LastLedgerSequence > 0 {
+		base.LastLedgerSequence = new(uint32)
+		*base.LastLedgerSequence = opts.LastLedgerSequence
+	}
+	if base.Flags == nil {
+		base.Flags = new(data.TransactionFlag)
+	}
+	// Without the flag a secp256k1 signature can be altered to give the
+	// transaction a different hash. Ed25519 signatures cannot be.
+	if !opts.NoCanonical && (key == nil || !IsEd25519(key, seq)) {
+		*base.Flags |= data.TxCanonicalSignature
+	}
+	switch {
+	case opts.Fee != nil:
+		base.Fee = *opts.Fee
+	case base.Fee.IsZero():
+		fee, err := data.NewNativeValue(DefaultFee)
+		if err != nil {
+			return err
+		}
+		base.Fee = *fee
+	}
+	return nil
+}
+
+// SetPublicKey sets the signing public key of a transaction left for
+// another signer.
+func SetPublicKey(tx data.Transaction, key crypto.Key, seq *uint32) {
+	base := tx.GetBase()
+	base.SigningPubKey = new(data.PublicKey)
+	copy(base.SigningPubKey[:], key.Public(seq))
+}
+
+// Sign signs a prepared transaction and sets its hash. A RemoteSigner is
+// asked for the signature.
+func Sign(tx data.Transaction, key crypto.Key, seq *uint32) error {
+	if signer, ok := key.(RemoteSigner); ok {
+		return signRemote(tx, signer)
+	}
+	return data.Sign(tx, key, seq)
+}
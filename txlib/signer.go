@@ -0,0 +1,52 @@
+package txlib
+
+import (
+	"github.com/rubblelabs/ripple/crypto"
+	"github.com/rubblelabs/ripple/data"
+)
+
+// RemoteSigner is a key whose private half is held by another system.
+// Private returns nil, and signatures are requested with Sign instead. The
+// message passed to Sign includes the signing prefix; ECDSA signers sign
+// the hash and ed25519 signers the message.
+type RemoteSigner interface {
+	crypto.Key
+	Sign(hash, msg []byte) ([]byte, error)
+}
+
+// RemotePublicKey implements the public half of crypto.Key for a remote
+// signer. Remote keys have no key sequences.
+type RemotePublicKey []byte
+
+func (k RemotePublicKey) Private(*uint32) []byte {
+	return nil
+}
+
+func (k RemotePublicKey) Public(*uint32) []byte {
+	return k
+}
+
+func (k RemotePublicKey) Id(*uint32) []byte {
+	return crypto.Sha256RipeMD160(k)
+}
+
+// signRemote is data.Sign for a remote signer.
+func signRemote(tx data.Transaction, signer RemoteSigner) error {
+	tx.InitialiseForSigning()
+	copy(tx.GetPublicKey().Bytes(), signer.Public(nil))
+	hash, msg, err := data.SigningHash(tx)
+	if err != nil {
+		return err
+	}
+	sig, err := signer.Sign(hash.Bytes(), append(tx.SigningPrefix().Bytes(), msg...))
+	if err != nil {
+		return err
+	}
+	*tx.GetSignature() = sig
+	hash, _, err = data.Raw(tx)
+	if err != nil {
+		return err
+	}
+	copy(tx.GetHash().Bytes(), hash.Bytes())
+	return nil
+}
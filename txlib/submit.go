@@ -0,0 +1,18 @@
+package txlib
+
+import (
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/ripple/websockets"
+)
+
+// Submit sends a signed transaction to the rippled websocket server at url.
+// The transaction can still fail or be queued, so check the engine result
+// and wait for it to be in a validated ledger.
+func Submit(url string, tx data.Transaction) (*websockets.SubmitResult, error) {
+	r, err := websockets.NewRemote(url)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return r.Submit(tx)
+}
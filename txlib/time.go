@@ -0,0 +1,86 @@
+package txlib
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rubblelabs/ripple/data"
+)
+
+// Seconds from the Unix epoch to the Ripple epoch, 2000-01-01T00:00:00Z
+const RippleEpoch = 946684800
+
+// Transaction fields holding seconds since the Ripple epoch
+var TimeFields = []string{"CancelAfter", "Expiration", "FinishAfter"}
+
+// ParseRippleTime reads an RFC 3339 time, or a duration from now like +48h,
+// as seconds since the Ripple epoch.
+func ParseRippleTime(s string) (uint32, error) {
+	var t time.Time
+	if strings.HasPrefix(s, "+") {
+		d, err := time.ParseDuration(s[1:])
+		if err != nil {
+			return 0, err
+		}
+		t = time.Now().Add(d)
+	} else {
+		var err error
+		if t, err = time.Parse(time.RFC3339, s); err != nil {
+			return 0, err
+		}
+	}
+	seconds := t.Unix() - RippleEpoch
+	if seconds < 0 || seconds > math.MaxUint32 {
+		return 0, fmt.Errorf("%s is out of range for a ledger time", s)
+	}
+	return uint32(seconds), nil
+}
+
+// ConvertTimes replaces time fields given as strings in transaction JSON
+// with their Ripple epoch seconds. Numbers are left as they are.
+func ConvertTimes(b []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	changed := false
+	for _, name := range TimeFields {
+		var s string
+		if json.Unmarshal(fields[name], &s) != nil {
+			continue
+		}
+		t, err := ParseRippleTime(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+		fields[name] = json.RawMessage(strconv.FormatUint(uint64(t), 10))
+		changed = true
+	}
+	if !changed {
+		return b, nil
+	}
+	return json.Marshal(fields)
+}
+
+// TxTimes returns the time fields set on tx.
+func TxTimes(tx data.Transaction) (map[string]*data.RippleTime, error) {
+	b, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	times := make(map[string]*data.RippleTime)
+	for _, name := range TimeFields {
+		if t, ok := fields[name].(float64); ok {
+			times[name] = data.NewRippleTime(uint32(t))
+		}
+	}
+	return times, nil
+}
@@ -0,0 +1,17 @@
+// Package txlib builds, signs and submits XRP Ledger transactions. It is the
+// library behind the tx command, for Go programs that want the same
+// builders and signers without running the binary.
+//
+// A transaction is built with its data type, or one of the New functions
+// here, then filled in with Prepare and signed with Sign:
+//
+//	payment := txlib.NewPayment(destination, amount)
+//	err := txlib.Prepare(payment, key, seq, txlib.Options{Sequence: 12})
+//	...
+//	err = txlib.Sign(payment, key, seq)
+//	...
+//	result, err := txlib.Submit("wss://s1.ripple.com:443", payment)
+//
+// Keys are any crypto.Key. Keys whose private half is held elsewhere
+// implement RemoteSigner.
+package txlib
@@ -0,0 +1,85 @@
+package txlib
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/rubblelabs/ripple/data"
+)
+
+// PaymentURI is a request for a payment, as
+//
+//	xrpl:rDestination?amount=1.5&dt=7
+//
+// with currency and issuer parameters for issued currencies. XRP amounts
+// are in XRP rather than drops. The amount may be left out for the payer to
+// choose.
+type PaymentURI struct {
+	Destination data.Account
+	Tag         *uint32
+	Amount      *data.Amount
+}
+
+// ParsePaymentURI parses an xrpl: payment URI, or the older ripple: form
+// some wallets still produce. The destination may be an X-address, whose tag
+// must then agree with any dt parameter.
+func ParsePaymentURI(s string) (*PaymentURI, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "xrpl" && u.Scheme != "ripple" {
+		return nil, fmt.Errorf("Not an xrpl: URI: %s", s)
+	}
+	address := u.Opaque
+	if address == "" {
+		address = u.Host
+	}
+	destination, tag, err := ParseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	p := &PaymentURI{Destination: *destination, Tag: tag}
+	query := u.Query()
+	if dt := query.Get("dt"); dt != "" {
+		t, err := strconv.ParseUint(dt, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Bad destination tag: %s", dt)
+		}
+		if tag != nil && *tag != uint32(t) {
+			return nil, fmt.Errorf("Destination tag %d conflicts with the X-address tag %d", t, *tag)
+		}
+		p.Tag = new(uint32)
+		*p.Tag = uint32(t)
+	}
+	if amount := query.Get("amount"); amount != "" {
+		s := amount + "/XRP"
+		if currency := query.Get("currency"); currency != "" && currency != "XRP" {
+			s = amount + "/" + currency + "/" + query.Get("issuer")
+		}
+		if p.Amount, err = data.NewAmount(s); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func (p *PaymentURI) String() string {
+	query := url.Values{}
+	if p.Tag != nil {
+		query.Set("dt", strconv.FormatUint(uint64(*p.Tag), 10))
+	}
+	if p.Amount != nil {
+		query.Set("amount", p.Amount.Value.String())
+		if !p.Amount.IsNative() {
+			query.Set("currency", p.Amount.Currency.String())
+			query.Set("issuer", p.Amount.Issuer.String())
+		}
+	}
+	s := "xrpl:" + p.Destination.String()
+	if len(query) > 0 {
+		s += "?" + query.Encode()
+	}
+	return s
+}
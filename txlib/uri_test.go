@@ -0,0 +1,67 @@
+package txlib
+
+import (
+	"testing"
+
+	"github.com/rubblelabs/ripple/data"
+)
+
+func TestParsePaymentURI(t *testing.T) {
+	for _, test := range []struct {
+		uri    string
+		tag    *uint32
+		amount string
+		ok     bool
+	}{
+		{"xrpl:rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf", nil, "", true},
+		{"ripple:rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf", nil, "", true},
+		{"xrpl://rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf", nil, "", true},
+		{"xrpl:rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf?amount=1.5&dt=7", uint32Ptr(7), "1.5/XRP", true},
+		{"xrpl:rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf?amount=2&currency=XRP", nil, "2/XRP", true},
+		{"xrpl:rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf?amount=10&currency=USD&issuer=rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", nil, "10/USD/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", true},
+		{"xrpl:XVLhHMPHU98es4dbozjVtdWzVrDjtV8xvjGQTYPiAx6gwDC", uint32Ptr(1), "", true},
+		{"xrpl:XVLhHMPHU98es4dbozjVtdWzVrDjtV8xvjGQTYPiAx6gwDC?dt=1", uint32Ptr(1), "", true},
+		// The tag conflicts with the X-address tag 1.
+		{"xrpl:XVLhHMPHU98es4dbozjVtdWzVrDjtV8xvjGQTYPiAx6gwDC?dt=2", nil, "", false},
+		{"xrpl:rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf?dt=x", nil, "", false},
+		{"xrpl:rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf?dt=4294967296", nil, "", false},
+		{"xrpl:rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf?amount=abc", nil, "", false},
+		{"xrpl:rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpe", nil, "", false},
+		{"bitcoin:rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf", nil, "", false},
+		{"rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf", nil, "", false},
+	} {
+		p, err := ParsePaymentURI(test.uri)
+		if (err == nil) != test.ok {
+			t.Errorf("ParsePaymentURI(%q) = %v, want ok %v", test.uri, err, test.ok)
+			continue
+		}
+		if !test.ok {
+			continue
+		}
+		if p.Destination.String() != "rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf" || !sameTag(p.Tag, test.tag) {
+			t.Errorf("ParsePaymentURI(%q) = %s tag %v, want tag %v", test.uri, p.Destination, p.Tag, test.tag)
+		}
+		switch {
+		case test.amount == "" && p.Amount != nil:
+			t.Errorf("ParsePaymentURI(%q) has amount %s", test.uri, p.Amount)
+		case test.amount != "":
+			want, err := data.NewAmount(test.amount)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Amount == nil || !p.Amount.Equals(*want) {
+				t.Errorf("ParsePaymentURI(%q) has amount %v, want %s", test.uri, p.Amount, want)
+			}
+		}
+
+		// The URI it writes reads back the same.
+		again, err := ParsePaymentURI(p.String())
+		if err != nil {
+			t.Errorf("ParsePaymentURI(%q): %s", p.String(), err)
+			continue
+		}
+		if !again.Destination.Equals(p.Destination) || !sameTag(again.Tag, p.Tag) || (again.Amount == nil) != (p.Amount == nil) || (p.Amount != nil && !again.Amount.Equals(*p.Amount)) {
+			t.Errorf("%q reads back as %q", test.uri, again)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package txlib
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/rubblelabs/ripple/crypto"
+	"github.com/rubblelabs/ripple/data"
+)
+
+// X-address prefixes. The account id, a tag flag byte and 8 bytes of tag
+// follow, of which only the low 4 are used.
+var (
+	xAddressMainnet = []byte{0x05, 0x44}
+	xAddressTestnet = []byte{0x04, 0x93}
+)
+
+// IsXAddress reports whether s looks like an X-address rather than a
+// classic address.
+func IsXAddress(s string) bool {
+	return strings.HasPrefix(s, "X") || strings.HasPrefix(s, "T")
+}
+
+// EncodeXAddress encodes an account and optional tag as an X-address, for a
+// test network if testnet is set.
+func EncodeXAddress(account data.Account, tag *uint32, testnet bool) string {
+	b := append([]byte{}, xAddressMainnet...)
+	if testnet {
+		b = append([]byte{}, xAddressTestnet...)
+	}
+	b = append(b, account.Bytes()...)
+	tagBytes := make([]byte, 9)
+	if tag != nil {
+		tagBytes[0] = 1
+		binary.LittleEndian.PutUint32(tagBytes[1:], *tag)
+	}
+	return crypto.Base58Encode(append(b, tagBytes...), crypto.ALPHABET)
+}
+
+// DecodeXAddress returns the account and tag of an X-address, and whether it
+// is for a test network. The tag is nil if the address has none.
+func DecodeXAddress(s string) (*data.Account, *uint32, bool, error) {
+	b, err := crypto.Base58Decode(s, crypto.ALPHABET)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	// The 4 byte checksum follows.
+	if len(b) != 35 {
+		return nil, nil, false, fmt.Errorf("Bad X-address length: %s", s)
+	}
+	b = b[:31]
+	var testnet bool
+	switch {
+	case bytes.Equal(b[:2], xAddressMainnet):
+	case bytes.Equal(b[:2], xAddressTestnet):
+		testnet = true
+	default:
+		return nil, nil, false, fmt.Errorf("Bad X-address prefix: %s", s)
+	}
+	var account data.Account
+	copy(account[:], b[2:22])
+	flag, tag, reserved := b[22], binary.LittleEndian.Uint32(b[23:27]), b[27:]
+	if flag > 1 || !bytes.Equal(reserved, make([]byte, 4)) || (flag == 0 && tag != 0) {
+		return nil, nil, false, fmt.Errorf("Unsupported X-address tag: %s", s)
+	}
+	if flag == 0 {
+		return &account, nil, testnet, nil
+	}
+	return &account, &tag, testnet, nil
+}
+
+// ParseAddress parses a classic address or an X-address, returning the tag
+// embedded in an X-address.
+func ParseAddress(s string) (*data.Account, *uint32, error) {
+	if !IsXAddress(s) {
+		account, err := data.NewAccountFromAddress(s)
+		return account, nil, err
+	}
+	account, tag, _, err := DecodeXAddress(s)
+	return account, tag, err
+}
@@ -0,0 +1,67 @@
+package txlib
+
+import (
+	"testing"
+
+	"github.com/rubblelabs/ripple/data"
+)
+
+// From the X-address specification, XLS-5d, for rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf
+var xAddressVectors = []struct {
+	tag     *uint32
+	testnet bool
+	address string
+}{
+	{nil, false, "XVLhHMPHU98es4dbozjVtdWzVrDjtV5fdx1mHp98tDMoQXb"},
+	{uint32Ptr(1), false, "XVLhHMPHU98es4dbozjVtdWzVrDjtV8xvjGQTYPiAx6gwDC"},
+	{uint32Ptr(14), false, "XVLhHMPHU98es4dbozjVtdWzVrDjtVoD9z4jAcBVsnb97sM"},
+	{uint32Ptr(11747), false, "XVLhHMPHU98es4dbozjVtdWzVrDjtV1N75zgFKga4R1B9Mk"},
+	{uint32Ptr(4294967295), false, "XVLhHMPHU98es4dbozjVtdWzVrDjtV18pX8yuPT7y4xaEHi"},
+	{nil, true, "TVE26TYGhfLC7tQDno7G8dGtxSkYQn49b3qD26PK7FcGSKE"},
+	{uint32Ptr(1), true, "TVE26TYGhfLC7tQDno7G8dGtxSkYQnSz1uDimDdPYXzSpyw"},
+	{uint32Ptr(4294967295), true, "TVE26TYGhfLC7tQDno7G8dGtxSkYQnXoy6kSDh6rZzApc69"},
+}
+
+func uint32Ptr(n uint32) *uint32 {
+	return &n
+}
+
+func sameTag(a, b *uint32) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+func TestEncodeXAddress(t *testing.T) {
+	account, err := data.NewAccountFromAddress("rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range xAddressVectors {
+		if got := EncodeXAddress(*account, test.tag, test.testnet); got != test.address {
+			t.Errorf("EncodeXAddress(%v, %v) = %s, want %s", test.tag, test.testnet, got, test.address)
+		}
+	}
+}
+
+func TestDecodeXAddress(t *testing.T) {
+	for _, test := range xAddressVectors {
+		account, tag, testnet, err := DecodeXAddress(test.address)
+		if err != nil {
+			t.Errorf("DecodeXAddress(%s): %s", test.address, err)
+			continue
+		}
+		if account.String() != "rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf" || !sameTag(tag, test.tag) || testnet != test.testnet {
+			t.Errorf("DecodeXAddress(%s) = %s, %v, %v, want tag %v, testnet %v", test.address, account, tag, testnet, test.tag, test.testnet)
+		}
+	}
+	for _, bad := range []string{
+		"",
+		// A character changed, failing the checksum
+		"XVLhHMPHU98es4dbozjVtdWzVrDjtV5fdx1mHp98tDMoQXc",
+		// A classic address
+		"rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf",
+	} {
+		if _, _, _, err := DecodeXAddress(bad); err == nil {
+			t.Errorf("DecodeXAddress(%q) succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+// isPaymentURI reports whether a --dest is a payment request URI.
+func isPaymentURI(s string) bool {
+	return strings.HasPrefix(s, "xrpl:") || strings.HasPrefix(s, "ripple:")
+}
+
+// parseRequest parses the payment --dest, which may be a payment request URI
+// giving the tag and amount as well. --tag and --amount must agree with the
+// URI, and its destination with --expect-dest.
+func parseRequest(c *cli.Context) (*data.Account, *uint32, *data.Amount) {
+	if !isPaymentURI(c.String("dest")) {
+		destination, tag := parseDestination(c)
+		return destination, tag, nil
+	}
+	request, err := txlib.ParsePaymentURI(c.String("dest"))
+	checkErr(err)
+	checkErr(checkExpectedDest(c.String("dest"), c.String("expect-dest"), &request.Destination))
+	if c.IsSet("tag") {
+		flagTag := uint32(c.Int("tag"))
+		if request.Tag != nil && *request.Tag != flagTag {
+			checkErr(fmt.Errorf("Destination tag %d conflicts with the requested tag %d", flagTag, *request.Tag))
+		}
+		request.Tag = &flagTag
+	}
+	if c.String("amount") != "" && request.Amount != nil {
+		if amount := parseAmount(c.String("amount")); !amount.Equals(*request.Amount) {
+			checkErr(fmt.Errorf("Amount %s conflicts with the requested amount %s", amount, request.Amount))
+		}
+	}
+	return &request.Destination, request.Tag, request.Amount
+}
+
+// paymentURI prints a payment request URI for a wallet to scan or pass to
+// payment --dest.
+func paymentURI(c *cli.Context) {
+	if c.String("dest") == "" {
+		fmt.Println("Destination is required")
+		os.Exit(1)
+	}
+	destination, tag := parseDestination(c)
+	request := &txlib.PaymentURI{Destination: *destination, Tag: tag}
+	if c.String("amount") != "" {
+		request.Amount = parseAmount(c.String("amount"))
+	}
+	fmt.Println(request)
+	printQR(c, request.String())
+}
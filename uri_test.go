@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/rubblelabs/tx/txlib"
+)
+
+func TestCheckExpectedDest(t *testing.T) {
+	for _, test := range []struct {
+		dest, expect string
+		ok           bool
+	}{
+		{"xrpl:rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf?amount=1", "", true},
+		{"xrpl:rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf?amount=1", "rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf", true},
+		{"ripple:rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf?dt=7", "rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf", true},
+		{"xrpl:rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf?amount=1", "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", false},
+		{"xrpl:rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf", "notanaddress", false},
+	} {
+		request, err := txlib.ParsePaymentURI(test.dest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := checkExpectedDest(test.dest, test.expect, &request.Destination); (err == nil) != test.ok {
+			t.Errorf("checkExpectedDest(%q, %q) = %v, want ok %v", test.dest, test.expect, err, test.ok)
+		}
+	}
+}
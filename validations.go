@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/codegangsta/cli"
+)
+
+// How many ledgers behind the newest validation a ledger is summarized,
+// leaving time for slower validators' validations to arrive
+const validationsLag = 2
+
+type validationMsg struct {
+	LedgerIndex         string `json:"ledger_index"`
+	LedgerHash          string `json:"ledger_hash"`
+	ValidationPublicKey string `json:"validation_public_key"`
+	MasterKey           string `json:"master_key"`
+	Full                bool   `json:"full"`
+}
+
+// ledgerValidations is who validated a ledger, by the hash they validated.
+type ledgerValidations struct {
+	LedgerIndex uint32              `json:"ledger_index"`
+	Hashes      map[string][]string `json:"hashes"`
+	Partial     []string            `json:"partial,omitempty"`
+	Missing     []string            `json:"missing,omitempty"`
+}
+
+// readUNL reads validator master keys from a file, one per line.
+func readUNL(name string) map[string]bool {
+	f, err := os.Open(name)
+	checkErr(err)
+	defer f.Close()
+	unl := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			unl[line] = true
+		}
+	}
+	checkErr(scanner.Err())
+	return unl
+}
+
+// summary finishes a ledger's validations, sorting them and listing the
+// validators in the UNL that did not validate it.
+func (l *ledgerValidations) summary(unl map[string]bool) {
+	seen := make(map[string]bool)
+	for _, validators := range l.Hashes {
+		sort.Strings(validators)
+		for _, v := range validators {
+			seen[v] = true
+		}
+	}
+	sort.Strings(l.Partial)
+	for v := range unl {
+		if !seen[v] {
+			l.Missing = append(l.Missing, v)
+		}
+	}
+	sort.Strings(l.Missing)
+}
+
+func printValidations(l *ledgerValidations, unl map[string]bool, verbose bool) {
+	var hashes []string
+	total := 0
+	for hash, validators := range l.Hashes {
+		hashes = append(hashes, hash)
+		total += len(validators)
+	}
+	// The hash most validated first, the others are forks or stragglers.
+	sort.Slice(hashes, func(i, j int) bool {
+		return len(l.Hashes[hashes[i]]) > len(l.Hashes[hashes[j]])
+	})
+	fmt.Printf("Ledger %d: %d validations\n", l.LedgerIndex, total)
+	for _, hash := range hashes {
+		fmt.Printf("  %s %d\n", hash, len(l.Hashes[hash]))
+		if verbose {
+			for _, v := range l.Hashes[hash] {
+				fmt.Printf("    %s\n", v)
+			}
+		}
+	}
+	if len(l.Partial) > 0 {
+		fmt.Printf("  Partial: %s\n", strings.Join(l.Partial, " "))
+	}
+	if len(unl) > 0 {
+		agreed := 0
+		if len(hashes) > 0 {
+			for _, v := range l.Hashes[hashes[0]] {
+				if unl[v] {
+					agreed++
+				}
+			}
+		}
+		// Validation needs 80% of the UNL to agree.
+		quorum := "reached"
+		if agreed*5 < len(unl)*4 {
+			quorum = "NOT reached"
+		}
+		fmt.Printf("  UNL: %d/%d agree, quorum %s\n", agreed, len(unl), quorum)
+		if len(l.Missing) > 0 {
+			fmt.Printf("  Missing: %s\n", strings.Join(l.Missing, " "))
+		}
+	}
+}
+
+// validations follows the validations stream and, as each ledger settles,
+// shows which validators signed which hash for it. With --unl it also
+// shows which listed validators were missing and if they reached quorum.
+func validations(c *cli.Context) {
+	var unl map[string]bool
+	if c.String("unl") != "" {
+		unl = readUNL(c.String("unl"))
+	}
+	pending := make(map[uint32]*ledgerValidations)
+	// Stragglers for a ledger already shown are dropped rather than shown
+	// again as a ledger with one validation.
+	var flushed uint32
+	checkErr(subscribe(c, map[string]interface{}{"streams": []string{"validations"}}, func(msgType string, b []byte) {
+		if msgType != "validationReceived" {
+			return
+		}
+		var msg validationMsg
+		checkErr(json.Unmarshal(b, &msg))
+		index, err := strconv.ParseUint(msg.LedgerIndex, 10, 32)
+		checkErr(err)
+		if uint32(index) <= flushed {
+			return
+		}
+		// Validators are known by their master key where they have one, as
+		// UNLs list them.
+		validator := msg.MasterKey
+		if validator == "" {
+			validator = msg.ValidationPublicKey
+		}
+		l := pending[uint32(index)]
+		if l == nil {
+			l = &ledgerValidations{LedgerIndex: uint32(index), Hashes: make(map[string][]string)}
+			pending[uint32(index)] = l
+		}
+		if msg.Full {
+			l.Hashes[msg.LedgerHash] = append(l.Hashes[msg.LedgerHash], validator)
+		} else {
+			l.Partial = append(l.Partial, validator)
+		}
+
+		var done []uint32
+		for i := range pending {
+			if i+validationsLag <= uint32(index) {
+				done = append(done, i)
+			}
+		}
+		sort.Slice(done, func(i, j int) bool { return done[i] < done[j] })
+		for _, i := range done {
+			l := pending[i]
+			delete(pending, i)
+			flushed = i
+			l.summary(unl)
+			if c.GlobalBool("json") {
+				outputJSON(l)
+			} else {
+				printValidations(l, unl, c.Bool("verbose"))
+			}
+		}
+	}))
+}
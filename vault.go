@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/rubblelabs/tx/txlib"
+)
+
+// vaultRequest calls the HashiCorp Vault HTTP API at VAULT_ADDR with
+// VAULT_TOKEN, decoding the data of the response into result.
+func vaultRequest(method, path string, body, result interface{}) error {
+	addr, token := os.Getenv("VAULT_ADDR"), os.Getenv("VAULT_TOKEN")
+	if addr == "" || token == "" {
+		return fmt.Errorf("VAULT_ADDR and VAULT_TOKEN are required")
+	}
+	var r bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&r).Encode(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, strings.TrimRight(addr, "/")+"/v1/"+strings.TrimLeft(path, "/"), &r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		Errors []string        `json:"errors"`
+		Data   json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("Vault %s: %s", path, resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK || len(response.Errors) > 0 {
+		return fmt.Errorf("Vault %s: %s %s", path, resp.Status, strings.Join(response.Errors, ", "))
+	}
+	return json.Unmarshal(response.Data, result)
+}
+
+// vaultSeed reads a seed from a key/value secret. Version 2 engines nest
+// the secret's fields in a second data object.
+func vaultSeed(path, field string) (string, error) {
+	var data map[string]interface{}
+	if err := vaultRequest("GET", path, nil, &data); err != nil {
+		return "", err
+	}
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		data = inner
+	}
+	seed, ok := data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("Vault secret %s has no %s field", path, field)
+	}
+	return seed, nil
+}
+
+// vaultTransitKey signs with an ed25519 key held by Vault's transit engine.
+// Transit does not support secp256k1.
+type vaultTransitKey struct {
+	txlib.RemotePublicKey
+	mount, name string
+}
+
+// newVaultTransitKey looks up a transit key given as mount/name, where the
+// mount defaults to transit.
+func newVaultTransitKey(s string) (*vaultTransitKey, error) {
+	k := &vaultTransitKey{mount: "transit", name: s}
+	if i := strings.LastIndex(s, "/"); i >= 0 {
+		k.mount, k.name = s[:i], s[i+1:]
+	}
+	var info struct {
+		Type          string `json:"type"`
+		LatestVersion int    `json:"latest_version"`
+		Keys          map[string]struct {
+			PublicKey string `json:"public_key"`
+		} `json:"keys"`
+	}
+	if err := vaultRequest("GET", k.mount+"/keys/"+k.name, nil, &info); err != nil {
+		return nil, err
+	}
+	if info.Type != "ed25519" {
+		return nil, fmt.Errorf("Vault transit key %s is %s, only ed25519 keys can sign", s, info.Type)
+	}
+	pub, err := base64.StdEncoding.DecodeString(info.Keys[fmt.Sprint(info.LatestVersion)].PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	k.RemotePublicKey = append([]byte{0xED}, pub...)
+	return k, nil
+}
+
+func (k *vaultTransitKey) Sign(hash, msg []byte) ([]byte, error) {
+	var result struct {
+		Signature string `json:"signature"`
+	}
+	body := map[string]string{"input": base64.StdEncoding.EncodeToString(msg)}
+	if err := vaultRequest("POST", k.mount+"/sign/"+k.name, body, &result); err != nil {
+		return nil, err
+	}
+	// Signatures are returned as vault:v<version>:<base64>
+	parts := strings.Split(result.Signature, ":")
+	return base64.StdEncoding.DecodeString(parts[len(parts)-1])
+}
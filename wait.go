@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/ripple/websockets"
+)
+
+// How often wait checks for the transaction, about once a ledger
+const waitInterval = 4 * time.Second
+
+// pollTx polls for a transaction until it is in a validated ledger and
+// returns it, or until the validated ledgers pass its LastLedgerSequence and
+// returns nil with the last validated ledger. lastLedger is updated to the
+// transaction's LastLedgerSequence once the server has seen it.
+func pollTx(c *cli.Context, hash data.Hash256, lastLedger *uint32) (*websockets.TxResult, uint32, error) {
+	r, err := websockets.NewRemote(server(c))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer r.Close()
+	for {
+		result, err := r.Tx(hash)
+		if e, ok := err.(*websockets.CommandError); err != nil && (!ok || e.Name != "txnNotFound") {
+			return nil, 0, err
+		}
+		if result != nil {
+			if result.Validated {
+				return result, result.LedgerSequence, nil
+			}
+			if last := result.GetBase().LastLedgerSequence; last != nil {
+				*lastLedger = *last
+			}
+		}
+
+		if *lastLedger > 0 {
+			var state serverStateResult
+			if err := request(c, "server_state", nil, &state); err != nil {
+				return nil, 0, err
+			}
+			if state.State.ValidatedLedger.Seq > *lastLedger {
+				return nil, state.State.ValidatedLedger.Seq, nil
+			}
+		}
+		time.Sleep(waitInterval)
+	}
+}
+
+// awaitTx is pollTx, exiting on errors.
+func awaitTx(c *cli.Context, hash data.Hash256, lastLedger *uint32) (*websockets.TxResult, uint32) {
+	result, ledger, err := pollTx(c, hash, lastLedger)
+	checkErr(err)
+	return result, ledger
+}
+
+// finalTx counts a transaction that has been validated, or has expired if
+// result is nil, and posts it to --notify-url.
+func finalTx(c *cli.Context, hash data.Hash256, result *websockets.TxResult, ledger uint32) {
+	if result == nil {
+		failedTotal.Inc()
+		notify(c, &notification{Hash: hash, Result: "expired", LedgerIndex: ledger})
+		return
+	}
+	validatedTotal.Inc()
+	if !result.MetaData.TransactionResult.Success() {
+		failedTotal.Inc()
+	}
+	notify(c, newNotification(&result.TransactionWithMetaData, ledger))
+}
+
+// wait blocks until a transaction is in a validated ledger, exiting 0 if it
+// succeeded and 1 if it failed, or until the validated ledgers pass its
+// LastLedgerSequence, exiting 2. The LastLedgerSequence is taken from the
+// transaction once the server has seen it, or from --lastledger.
+func wait(c *cli.Context) {
+	if c.Args().First() == "" {
+		fmt.Println("Transaction hash is required")
+		os.Exit(1)
+	}
+	hash := parseHash(c.Args().First())
+	lastLedger := uint32(c.GlobalInt("lastledger"))
+	serveMetrics(c)
+	result, ledger := awaitTx(c, *hash, &lastLedger)
+	finalTx(c, *hash, result, ledger)
+	if result == nil {
+		fmt.Printf("Expired: ledger %d passed LastLedgerSequence %d\n", ledger, lastLedger)
+		os.Exit(2)
+	}
+	txm := &result.TransactionWithMetaData
+	fmt.Printf("%s %s in ledger %d\n", result.GetType(), colorResult(result.MetaData.TransactionResult), result.LedgerSequence)
+	checkDelivered(txm)
+	if !result.MetaData.TransactionResult.Success() {
+		os.Exit(1)
+	}
+}
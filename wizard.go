@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+// prompter asks questions on stderr and reads the answers from stdin.
+type prompter struct {
+	in *bufio.Reader
+}
+
+// ask prompts until parse accepts the answer. An empty answer is replaced
+// by def, unless def is empty too.
+func (p *prompter) ask(question, def string, parse func(string) error) string {
+	for {
+		if def != "" {
+			fmt.Fprintf(os.Stderr, "%s [%s]: ", question, def)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s: ", question)
+		}
+		line, err := p.in.ReadString('\n')
+		if err != nil && line == "" {
+			checkErr(fmt.Errorf("Wizard cancelled"))
+		}
+		answer := strings.TrimSpace(line)
+		if answer == "" {
+			answer = def
+		}
+		if err := parse(answer); err != nil {
+			fmt.Fprintf(os.Stderr, "  %s\n", err)
+			continue
+		}
+		return answer
+	}
+}
+
+// confirm asks a yes or no question.
+func (p *prompter) confirm(question string, def bool) bool {
+	choices := "y/N"
+	if def {
+		choices = "Y/n"
+	}
+	var yes bool
+	p.ask(question+" "+choices, "", func(s string) error {
+		switch strings.ToLower(s) {
+		case "":
+			yes = def
+		case "y", "yes":
+			yes = true
+		case "n", "no":
+			yes = false
+		default:
+			return fmt.Errorf("Answer y or n")
+		}
+		return nil
+	})
+	return yes
+}
+
+func required(s string) error {
+	if s == "" {
+		return fmt.Errorf("Required")
+	}
+	return nil
+}
+
+func optionalUint32(s string) error {
+	if s == "" {
+		return nil
+	}
+	if _, err := strconv.ParseUint(s, 10, 32); err != nil {
+		return fmt.Errorf("Enter a whole number")
+	}
+	return nil
+}
+
+func wizardPayment(p *prompter, c *cli.Context) data.Transaction {
+	var destination *data.Account
+	var tag *uint32
+	p.ask("Destination address, X-address or @name", "", func(s string) error {
+		if err := required(s); err != nil {
+			return err
+		}
+		var err error
+		destination, tag, err = resolveAddress(s)
+		if err == nil && *destination == *keyAccount(c) {
+			err = fmt.Errorf("Destination is the signing account")
+		}
+		return err
+	})
+	def := ""
+	if tag != nil {
+		def = strconv.FormatUint(uint64(*tag), 10)
+	}
+	if s := p.ask("Destination tag, blank for none", def, optionalUint32); s != "" {
+		t, _ := strconv.ParseUint(s, 10, 32)
+		tag = new(uint32)
+		*tag = uint32(t)
+	}
+	var amount *data.Amount
+	p.ask("Amount, in drops or as value/currency/issuer", "", func(s string) error {
+		if err := required(s); err != nil {
+			return err
+		}
+		var err error
+		amount, err = data.NewAmount(s)
+		return err
+	})
+	payment := txlib.NewPayment(*destination, *amount)
+	payment.DestinationTag = tag
+	if memo := p.ask("Memo, blank for none", "", func(string) error { return nil }); memo != "" {
+		payment.Memos = data.Memos{txlib.NewTextMemo(memo)}
+	}
+	return payment
+}
+
+func wizardTrust(p *prompter, c *cli.Context) data.Transaction {
+	var limit *data.Amount
+	p.ask("Limit as value/currency/issuer", "", func(s string) error {
+		if err := required(s); err != nil {
+			return err
+		}
+		var err error
+		if limit, err = data.NewAmount(s); err == nil && limit.IsNative() {
+			err = fmt.Errorf("Trust lines are for issued currencies, not XRP")
+		}
+		return err
+	})
+	tx := txlib.NewTrustSet(*limit)
+	if p.confirm("Stop rippling through this line?", true) {
+		*tx.Flags |= data.TxSetNoRipple
+	}
+	return tx
+}
+
+// wizard walks through building a transaction, then signs it and offers to
+// submit it.
+func wizard(c *cli.Context) {
+	if !haveSigner(c) {
+		fmt.Println("Seed is required")
+		os.Exit(1)
+	}
+	p := &prompter{in: bufio.NewReader(os.Stdin)}
+	builders := map[string]func(*prompter, *cli.Context) data.Transaction{
+		"payment": wizardPayment,
+		"trust":   wizardTrust,
+	}
+	typ := p.ask("Transaction type, payment or trust", "payment", func(s string) error {
+		if builders[s] == nil {
+			return fmt.Errorf("Choose payment or trust")
+		}
+		return nil
+	})
+	tx := builders[typ](p, c)
+	fmt.Fprintf(os.Stderr, "Signing for %s\n", keyAccount(c))
+
+	sequence := strconv.Itoa(c.GlobalInt("sequence"))
+	if !c.GlobalIsSet("sequence") {
+		sequence = ""
+		if info, err := newRemote(c).AccountInfo(*keyAccount(c)); err == nil && info.AccountData.Sequence != nil {
+			sequence = strconv.FormatUint(uint64(*info.AccountData.Sequence), 10)
+		}
+	}
+	s := p.ask("Sequence", sequence, func(s string) error {
+		if err := required(s); err != nil {
+			return err
+		}
+		return optionalUint32(s)
+	})
+	// Set as flags, so sign checks them as it would if they were given.
+	checkErr(c.GlobalSet("sequence", s))
+
+	fee := strconv.Itoa(c.GlobalInt("fee"))
+	if c.GlobalString("fee-strategy") == "server" && !c.GlobalIsSet("fee") {
+		drops, err := openLedgerDrops(c)
+		checkErr(err)
+		fee = strconv.FormatInt(drops, 10)
+	}
+	s = p.ask("Fee in drops", fee, func(s string) error {
+		drops, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return fmt.Errorf("Enter a whole number of drops")
+		}
+		if c.GlobalInt("max-fee") > 0 && drops > uint64(c.GlobalInt("max-fee")) {
+			return fmt.Errorf("More than --max-fee %d drops", c.GlobalInt("max-fee"))
+		}
+		return nil
+	})
+	checkErr(c.GlobalSet("fee", s))
+	opts, err := signOptions(c)
+	checkErr(err)
+	checkErr(txlib.Prepare(tx, key, keySequence, opts))
+
+	b, err := json.MarshalIndent(tx, "", "  ")
+	checkErr(err)
+	var fields map[string]interface{}
+	checkErr(json.Unmarshal(b, &fields))
+	delete(fields, "hash")
+	b, err = json.MarshalIndent(fields, "", "  ")
+	checkErr(err)
+	fmt.Fprintf(os.Stderr, "\n%s\n\n", b)
+	if !p.confirm("Sign this transaction?", false) {
+		checkErr(fmt.Errorf("Not signed"))
+	}
+	sign(c, tx)
+	if c.GlobalBool("unsigned") {
+		outputUnsignedTx(c, tx)
+		return
+	}
+	hash, raw, err := data.Raw(tx)
+	checkErr(err)
+	fmt.Printf("Hash: %s\nRaw: %X\n", hash, raw)
+	printExplorerLinks(c, tx)
+	if p.confirm(fmt.Sprintf("Submit to %s?", server(c)), false) {
+		submitTx(c, tx)
+	}
+}
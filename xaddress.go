@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codegangsta/cli"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/tx/txlib"
+)
+
+// parseAddress parses a classic address, an X-address or an address book
+// @name, returning the tag embedded in an X-address or saved for the name.
+func parseAddress(s string) (*data.Account, *uint32) {
+	account, tag, err := resolveAddress(s)
+	checkErr(err)
+	return account, tag
+}
+
+// parseDestination parses the --dest flag, combining any tag embedded in an
+// X-address with the --tag flag. Conflicting tags are an error, but --tag
+// replaces the tag saved for an address book name. With --expect-dest the
+// destination must resolve to that address.
+func parseDestination(c *cli.Context) (*data.Account, *uint32) {
+	account, tag := parseAddress(c.String("dest"))
+	checkErr(checkExpectedDest(c.String("dest"), c.String("expect-dest"), account))
+	if c.IsSet("tag") {
+		flagTag := uint32(c.Int("tag"))
+		if tag != nil && *tag != flagTag && !isAlias(c.String("dest")) {
+			checkErr(fmt.Errorf("Destination tag %d conflicts with the X-address tag %d", flagTag, *tag))
+		}
+		tag = &flagTag
+	}
+	return account, tag
+}
+
+// checkExpectedDest returns an error unless the account dest resolved to is
+// the address expect resolves to. An empty expect accepts any account.
+func checkExpectedDest(dest, expect string, account *data.Account) error {
+	if expect == "" {
+		return nil
+	}
+	expected, _, err := resolveAddress(expect)
+	if err != nil {
+		return err
+	}
+	if *expected != *account {
+		return fmt.Errorf("%s resolved to %s, not the expected %s", dest, account, expected)
+	}
+	return nil
+}
+
+func xaddress(c *cli.Context) {
+	if c.Args().First() == "" {
+		fmt.Println("Address is required")
+		os.Exit(1)
+	}
+	s := c.Args().First()
+
+	if txlib.IsXAddress(s) {
+		account, tag, testnet, err := txlib.DecodeXAddress(s)
+		checkErr(err)
+		if c.GlobalBool("json") {
+			outputJSON(map[string]interface{}{"account": account, "tag": tag, "testnet": testnet})
+			return
+		}
+		fmt.Printf("Account: %s\n", account)
+		if tag != nil {
+			fmt.Printf("Tag: %d\n", *tag)
+		}
+		if testnet {
+			fmt.Println("Network: test")
+		}
+		printQR(c, account.String())
+		return
+	}
+
+	account := parseAccount(s)
+	var tag *uint32
+	if c.IsSet("tag") {
+		tag = new(uint32)
+		*tag = uint32(c.Int("tag"))
+	}
+	x := txlib.EncodeXAddress(*account, tag, c.Bool("test"))
+	fmt.Println(x)
+	printQR(c, x)
+}
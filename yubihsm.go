@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+)
+
+// yubiHSMModule is Yubico's PKCS#11 module, which talks to a YubiHSM 2
+// through the yubihsm-connector.
+func yubiHSMModule() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "yubihsm_pkcs11.dylib"
+	case "windows":
+		return "yubihsm_pkcs11.dll"
+	default:
+		return "yubihsm_pkcs11.so"
+	}
+}
+
+// newYubiHSMKey opens a secp256k1 key on a YubiHSM 2. The module reads the
+// connector url from the file named by YUBIHSM_PKCS11_CONF when it is
+// initialized, and takes the authentication key id in hex followed by its
+// password as the PIN.
+func newYubiHSMKey(module, connector string, authKey int, label, password string) (*pkcs11Key, error) {
+	conf, err := ioutil.TempFile("", "yubihsm_pkcs11_*.conf")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(conf.Name())
+	_, err = fmt.Fprintf(conf, "connector = %s\n", connector)
+	if cerr := conf.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Setenv("YUBIHSM_PKCS11_CONF", conf.Name()); err != nil {
+		return nil, err
+	}
+	return newPKCS11Key(module, 0, label, fmt.Sprintf("%04x%s", authKey, password))
+}